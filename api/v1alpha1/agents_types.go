@@ -30,12 +30,19 @@ type AgentsSpec struct {
 
 // AgentsStatus defines the observed state of Agents
 type AgentsStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	CommonStatus `json:",inline"`
+
+	// Template is the name and version of the template resolved for the instance.
+	Template TemplateReference `json:"template,omitempty"`
+	// Revision is the source revision of the template, e.g. the git commit it was synced from.
+	Revision string `json:"revision,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Agents is the Schema for the agents API
 type Agents struct {
@@ -8,16 +8,22 @@ import (
 	networking_v1 "k8s.io/api/networking/v1"
 	rbac_v1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type AppSpec struct {
-	Name      string   `json:"name,omitempty"`
-	Namespace string   `json:"namespace,omitempty"`
-	Template  Template `json:"template"`
-	Singleton bool     `json:"singleton,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Template  TemplateReference `json:"template"`
+	Singleton bool              `json:"singleton,omitempty"`
 
 	Registry string `json:"registry,omitempty"`
 
+	// Values are the free-form values the template files are rendered against.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+optional
+	Values *runtime.RawExtension `json:"values,omitempty"`
+
 	CommonSpec `json:",inline"`
 	Components map[string]ComponentSpec `json:"components,omitempty"`
 
@@ -25,8 +31,11 @@ type AppSpec struct {
 	Dependencies AppDepsSpec `json:"deps,omitempty"`
 }
 
-type Template struct {
-	Name    string `json:"name"`
+type TemplateReference struct {
+	Name string `json:"name"`
+	// Version of the template, either an exact version or a semantic version constraint such
+	// as "~1.4", "^2" or ">=1.2 <2" resolved to the highest matching version. The latest version
+	// is used when omitted.
 	Version string `json:"version,omitempty"`
 }
 
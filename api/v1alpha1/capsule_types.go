@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -25,9 +26,14 @@ import (
 
 // CapsuleSpec defines the desired state of Capsule
 type CapsuleSpec struct {
-	Name      string   `json:"name,omitempty"`
-	Namespace string   `json:"namespace,omitempty"`
-	Template  Template `json:"template"`
+	Name      string            `json:"name,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Template  TemplateReference `json:"template"`
+
+	// Values are the free-form values the capsule files are rendered against.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+optional
+	Values *runtime.RawExtension `json:"values,omitempty"`
 
 	CapsuleCommonSpec `json:",inline"`
 
@@ -41,12 +47,19 @@ type CapsuleCommonSpec struct {
 
 // CapsuleStatus defines the observed state of Capsule
 type CapsuleStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	CommonStatus `json:",inline"`
+
+	// Template is the name and version of the template resolved for the instance.
+	Template TemplateReference `json:"template,omitempty"`
+	// Revision is the source revision of the template, e.g. the git commit it was synced from.
+	Revision string `json:"revision,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Capsule is the Schema for the agents API
 type Capsule struct {
@@ -31,12 +31,16 @@ type ExportersSpec struct {
 
 // ExportersStatus defines the observed state of Exporters
 type ExportersStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	CommonStatus `json:",inline"`
+
+	Exployments map[string]ApploymentStatus `json:"exployments,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Exporters is the Schema for the exporters API
 type Exporters struct {
@@ -0,0 +1,65 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types reported in the status of the managed instances.
+const (
+	// ConditionReady aggregates all the other conditions.
+	ConditionReady string = "Ready"
+	// ConditionTemplateResolved reports whether the template was found and rendered.
+	ConditionTemplateResolved string = "TemplateResolved"
+	// ConditionDependenciesReady reports whether the dependencies (capsules) were created.
+	ConditionDependenciesReady string = "DependenciesReady"
+	// ConditionApplied reports whether the rendered manifests were applied to the cluster.
+	ConditionApplied string = "Applied"
+)
+
+// Condition reasons reported in the status of the managed instances.
+const (
+	ReasonSucceeded        string = "Succeeded"
+	ReasonResolveFailed    string = "ResolveFailed"
+	ReasonDependencyFailed string = "DependencyFailed"
+	ReasonApplyFailed      string = "ApplyFailed"
+	// ReasonBlocked marks the conditions of the steps not run because an earlier step failed.
+	ReasonBlocked string = "Blocked"
+)
+
+// CommonStatus defines the observed state shared by all the managed instances.
+type CommonStatus struct {
+	// ObservedGeneration is the most recent generation observed by the operator.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the instance.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastError is the message of the error which keeps the instance from being ready.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// ApploymentPhase is the phase of a single apployment within an instance.
+type ApploymentPhase string
+
+const (
+	PhaseRendering ApploymentPhase = "Rendering"
+	PhaseApplying  ApploymentPhase = "Applying"
+	PhaseReady     ApploymentPhase = "Ready"
+	PhaseFailed    ApploymentPhase = "Failed"
+)
+
+// ApploymentStatus defines the observed state of a single apployment within an instance.
+type ApploymentStatus struct {
+	// Template is the name and version of the template resolved for the apployment.
+	Template TemplateReference `json:"template,omitempty"`
+	// Revision is the source revision of the template, e.g. the git commit it was synced from.
+	Revision string `json:"revision,omitempty"`
+
+	Phase              ApploymentPhase `json:"phase,omitempty"`
+	LastTransitionTime metav1.Time     `json:"lastTransitionTime,omitempty"`
+
+	// Error is the message of the error which made the apployment fail.
+	Error string `json:"error,omitempty"`
+}
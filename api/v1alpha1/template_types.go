@@ -0,0 +1,101 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition types and reasons reported in the status of the templates.
+const (
+	// ConditionTemplateLoaded reports whether the files of the template were loaded into the store.
+	ConditionTemplateLoaded string = "Loaded"
+
+	ReasonLoadFailed string = "LoadFailed"
+)
+
+// TemplateSpec defines a version of a template published in the cluster
+type TemplateSpec struct {
+	// Category of the template, the templates of a category are only served if the category is
+	// configured to be stored in the cluster.
+	//+kubebuilder:default=apps
+	//+optional
+	Category string `json:"category,omitempty"`
+
+	// Name of the template, as referenced by the instances.
+	//+kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9.-]*$`
+	Name string `json:"name"`
+	// Version of the template, e.g. v1.0.0.
+	//+kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9.+-]*$`
+	Version string `json:"version"`
+
+	// Files of the template keyed by their path in the template, e.g. `template.yaml` or
+	// `<component>/deployment.yaml`.
+	//+optional
+	Files map[string]string `json:"files,omitempty"`
+
+	// ConfigMaps holding files of the template, the files inline take precedence.
+	//+optional
+	ConfigMaps []TemplateConfigMapSource `json:"configMaps,omitempty"`
+}
+
+// TemplateConfigMapSource references a ConfigMap whose keys are files of the template.
+type TemplateConfigMapSource struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+
+	// Path of the folder the keys of the ConfigMap are placed in, e.g. the component name.
+	// The keys are placed at the root of the template if empty.
+	//+optional
+	Path string `json:"path,omitempty"`
+}
+
+// TemplateStatus defines the observed state of Template
+type TemplateStatus struct {
+	CommonStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Template",type=string,JSONPath=`.spec.name`
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
+//+kubebuilder:printcolumn:name="Category",type=string,JSONPath=`.spec.category`
+//+kubebuilder:printcolumn:name="Loaded",type=string,JSONPath=`.status.conditions[?(@.type=="Loaded")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Template is the Schema for the templates API
+type Template struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TemplateSpec   `json:"spec,omitempty"`
+	Status TemplateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// TemplateList contains a list of Template
+type TemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Template `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Template{}, &TemplateList{})
+}
@@ -65,6 +65,12 @@ type Manifests struct {
 type AppManifests struct {
 	Manifests
 
+	// TemplateName and TemplateVersion identify the template the manifests are rendered from,
+	// TemplateRevision is the source revision of the template if known.
+	TemplateName     string
+	TemplateVersion  string
+	TemplateRevision string
+
 	CompsMenifests []*CompManifests
 }
 
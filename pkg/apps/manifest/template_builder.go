@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"fmt"
 	"regexp"
 
 	app_v1 "k8s.io/api/apps/v1"
@@ -15,83 +16,90 @@ import (
 )
 
 type Builder interface {
-	Build() *AppManifests
+	Build() (*AppManifests, error)
 }
 
-func NewTemplateBuilder(template *template.AppTemplate) Builder {
+// NewTemplateBuilder returns the builder of the manifests of the template, the template files
+// are rendered against the values.
+func NewTemplateBuilder(template *template.AppTemplate, values *template.Values) Builder {
 	if template == nil {
 		return nil
 	}
 	return &templateBuilder{
 		template: template,
+		values:   values,
 	}
 }
 
 type templateBuilder struct {
 	template *template.AppTemplate
+	values   *template.Values
 }
 
-func (b *templateBuilder) Build() *AppManifests {
+func (b *templateBuilder) Build() (*AppManifests, error) {
 	manifests := &AppManifests{}
 	for _, tempFile := range b.template.TemplateFiles {
 		resType, _ := recognize(tempFile)
-		var err error
+		content, err := tempFile.Render(b.values)
+		if err != nil {
+			return nil, err
+		}
 
 		switch resType {
 		case ConfigMap:
 			cm := core_v1.ConfigMap{}
-			err = yaml.Unmarshal(tempFile.Content, &cm)
+			err = yaml.Unmarshal(content, &cm)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ConfigMaps = append(manifests.ConfigMaps, &cm)
 		case Secret:
 			sec := &core_v1.Secret{}
-			err = yaml.Unmarshal(tempFile.Content, sec)
+			err = yaml.Unmarshal(content, sec)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Secrets = append(manifests.Secrets, sec)
 		case ServiceAccount:
 			sa := &core_v1.ServiceAccount{}
-			err = yaml.Unmarshal(tempFile.Content, sa)
+			err = yaml.Unmarshal(content, sa)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ServiceAccount = sa
 		case ClusterRole:
 			role := &rbac_v1.ClusterRole{}
-			err = yaml.Unmarshal(tempFile.Content, role)
+			err = yaml.Unmarshal(content, role)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ClusterRole = role
 		case ClusterRoleBinding:
 			rb := &rbac_v1.ClusterRoleBinding{}
-			err = yaml.Unmarshal(tempFile.Content, rb)
+			err = yaml.Unmarshal(content, rb)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ClusterRoleBinding = rb
 		case Role:
 			role := &rbac_v1.Role{}
-			err = yaml.Unmarshal(tempFile.Content, role)
+			err = yaml.Unmarshal(content, role)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Role = role
 		case RoleBinding:
 			rb := &rbac_v1.RoleBinding{}
-			err = yaml.Unmarshal(tempFile.Content, rb)
+			err = yaml.Unmarshal(content, rb)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.RoleBinding = rb
 		case Ingress:
 			ing := &networking_v1.Ingress{}
-			err = yaml.Unmarshal(tempFile.Content, ing)
+			err = yaml.Unmarshal(content, ing)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Ingress = ing
 
@@ -100,136 +108,189 @@ func (b *templateBuilder) Build() *AppManifests {
 	}
 
 	for _, comp := range b.template.Workloads {
-		manifests.CompsMenifests = append(manifests.CompsMenifests, b.BuildComp(comp))
+		compManifests, err := b.BuildComp(comp)
+		if err != nil {
+			return nil, err
+		}
+		manifests.CompsMenifests = append(manifests.CompsMenifests, compManifests)
 	}
 
-	return manifests
+	return manifests, nil
 }
 
-func (b *templateBuilder) BuildComp(template *template.WorkloadTemplate) *CompManifests {
+func (b *templateBuilder) BuildComp(template *template.WorkloadTemplate) (*CompManifests, error) {
 	manifests := &CompManifests{Name: template.Name}
 	for _, tempFile := range template.TemplateFiles {
 		resType, _ := recognize(tempFile)
-		var err error
+		content, err := tempFile.Render(b.values)
+		if err != nil {
+			return nil, err
+		}
 
 		switch resType {
 		case ConfigMap:
 			cm := &core_v1.ConfigMap{}
-			err = yaml.Unmarshal(tempFile.Content, cm)
+			err = yaml.Unmarshal(content, cm)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ConfigMaps = append(manifests.ConfigMaps, cm)
 		case Secret:
 			sec := &core_v1.Secret{}
-			err = yaml.Unmarshal(tempFile.Content, sec)
+			err = yaml.Unmarshal(content, sec)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Secrets = append(manifests.Secrets, sec)
 		case ServiceAccount:
 			sa := &core_v1.ServiceAccount{}
-			err = yaml.Unmarshal(tempFile.Content, sa)
+			err = yaml.Unmarshal(content, sa)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ServiceAccount = sa
 		case ClusterRole:
 			role := &rbac_v1.ClusterRole{}
-			err = yaml.Unmarshal(tempFile.Content, role)
+			err = yaml.Unmarshal(content, role)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ClusterRole = role
 		case ClusterRoleBinding:
 			rb := &rbac_v1.ClusterRoleBinding{}
-			err = yaml.Unmarshal(tempFile.Content, rb)
+			err = yaml.Unmarshal(content, rb)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ClusterRoleBinding = rb
 		case Role:
 			role := &rbac_v1.Role{}
-			err = yaml.Unmarshal(tempFile.Content, role)
+			err = yaml.Unmarshal(content, role)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Role = role
 		case RoleBinding:
 			rb := &rbac_v1.RoleBinding{}
-			err = yaml.Unmarshal(tempFile.Content, rb)
+			err = yaml.Unmarshal(content, rb)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.RoleBinding = rb
 		case Ingress:
 			ing := &networking_v1.Ingress{}
-			err = yaml.Unmarshal(tempFile.Content, ing)
+			err = yaml.Unmarshal(content, ing)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Ingress = ing
 		case Service:
 			svc := &core_v1.Service{}
-			err = yaml.Unmarshal(tempFile.Content, svc)
+			err = yaml.Unmarshal(content, svc)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Services = append(manifests.Services, svc)
 		case Deployment:
 			wl := &app_v1.Deployment{}
-			err = yaml.Unmarshal(tempFile.Content, wl)
+			err = yaml.Unmarshal(content, wl)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Deployment = wl
 		case DaemonSet:
 			wl := &app_v1.DaemonSet{}
-			err = yaml.Unmarshal(tempFile.Content, wl)
+			err = yaml.Unmarshal(content, wl)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.DaemonSet = wl
 		case StatefulSet:
 			wl := &app_v1.StatefulSet{}
-			err = yaml.Unmarshal(tempFile.Content, wl)
+			err = yaml.Unmarshal(content, wl)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.StatefulSet = wl
 		case ReplicaSet:
 			wl := &app_v1.ReplicaSet{}
-			err = yaml.Unmarshal(tempFile.Content, wl)
+			err = yaml.Unmarshal(content, wl)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.ReplicaSet = wl
 		case Job:
 			wl := &batch_v1.Job{}
-			err = yaml.Unmarshal(tempFile.Content, wl)
+			err = yaml.Unmarshal(content, wl)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.Job = wl
 		case CronJob:
 			wl := &batch_v1.CronJob{}
-			err = yaml.Unmarshal(tempFile.Content, wl)
+			err = yaml.Unmarshal(content, wl)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.CronJob = wl
 		case HPA:
 			hpa := &autoscaling_v1.HorizontalPodAutoscaler{}
-			err = yaml.Unmarshal(tempFile.Content, hpa)
+			err = yaml.Unmarshal(content, hpa)
 			if err != nil {
-				panic(err)
+				return nil, invalidTemplate(tempFile, err)
 			}
 			manifests.HPA = hpa
 		default:
 		}
 	}
 
-	return manifests
+	return manifests, nil
+}
+
+func invalidTemplate(file *template.TemplateFile, err error) error {
+	return fmt.Errorf("%w: cannot decode %s: %v", template.ErrInvalidTemplate, file.FileName, err)
+}
+
+// Schema returns the empty resource of the template file, i.e. the schema the content of the file
+// is patched against, false if the file holds no known resource.
+func Schema(file *template.TemplateFile) (interface{}, bool) {
+	resType, _ := recognize(file)
+	switch resType {
+	case ConfigMap:
+		return &core_v1.ConfigMap{}, true
+	case Secret:
+		return &core_v1.Secret{}, true
+	case ServiceAccount:
+		return &core_v1.ServiceAccount{}, true
+	case ClusterRole:
+		return &rbac_v1.ClusterRole{}, true
+	case ClusterRoleBinding:
+		return &rbac_v1.ClusterRoleBinding{}, true
+	case Role:
+		return &rbac_v1.Role{}, true
+	case RoleBinding:
+		return &rbac_v1.RoleBinding{}, true
+	case Ingress:
+		return &networking_v1.Ingress{}, true
+	case Service:
+		return &core_v1.Service{}, true
+	case Deployment:
+		return &app_v1.Deployment{}, true
+	case DaemonSet:
+		return &app_v1.DaemonSet{}, true
+	case StatefulSet:
+		return &app_v1.StatefulSet{}, true
+	case ReplicaSet:
+		return &app_v1.ReplicaSet{}, true
+	case Job:
+		return &batch_v1.Job{}, true
+	case CronJob:
+		return &batch_v1.CronJob{}, true
+	case HPA:
+		return &autoscaling_v1.HorizontalPodAutoscaler{}, true
+	default:
+		return nil, false
+	}
 }
 
 func recognize(file *template.TemplateFile) (ManifestType, string) {
@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -89,9 +90,29 @@ func Test_templateBuilder_Build(t *testing.T) {
 			b := &templateBuilder{
 				template: tt.fields.template,
 			}
-			if got := b.Build(); !reflect.DeepEqual(got, tt.want) {
+			got, err := b.Build()
+			if err != nil {
+				t.Errorf("templateBuilder.Build() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("templateBuilder.Build() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_templateBuilder_Build_invalid(t *testing.T) {
+	b := &templateBuilder{
+		template: &template.AppTemplate{TemplateBase: template.TemplateBase{
+			Name:    "app",
+			Version: "version",
+			TemplateFiles: []*template.TemplateFile{
+				{FileName: "app_configmap.yaml", Content: []byte("data: [")},
+			},
+		}},
+	}
+	if _, err := b.Build(); !errors.Is(err, template.ErrInvalidTemplate) {
+		t.Errorf("templateBuilder.Build() error = %v, want %v", err, template.ErrInvalidTemplate)
+	}
+}
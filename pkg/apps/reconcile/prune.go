@@ -0,0 +1,149 @@
+package reconcile
+
+import (
+	"context"
+
+	"github.com/go-kit/log/level"
+	"github.com/udmire/observability-operator/pkg/apps/manifest"
+	util_client "github.com/udmire/observability-operator/pkg/utils/client"
+	app_v1 "k8s.io/api/apps/v1"
+	autoscaling_v1 "k8s.io/api/autoscaling/v1"
+	batch_v1 "k8s.io/api/batch/v1"
+	core_v1 "k8s.io/api/core/v1"
+	networking_v1 "k8s.io/api/networking/v1"
+	rbac_v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// namespacedLists are the namespaced kinds of the objects rendered from the templates.
+var namespacedLists = []func() client.ObjectList{
+	func() client.ObjectList { return &core_v1.ConfigMapList{} },
+	func() client.ObjectList { return &core_v1.SecretList{} },
+	func() client.ObjectList { return &core_v1.ServiceList{} },
+	func() client.ObjectList { return &core_v1.ServiceAccountList{} },
+	func() client.ObjectList { return &rbac_v1.RoleList{} },
+	func() client.ObjectList { return &rbac_v1.RoleBindingList{} },
+	func() client.ObjectList { return &networking_v1.IngressList{} },
+	func() client.ObjectList { return &app_v1.DeploymentList{} },
+	func() client.ObjectList { return &app_v1.DaemonSetList{} },
+	func() client.ObjectList { return &app_v1.StatefulSetList{} },
+	func() client.ObjectList { return &app_v1.ReplicaSetList{} },
+	func() client.ObjectList { return &batch_v1.JobList{} },
+	func() client.ObjectList { return &batch_v1.CronJobList{} },
+	func() client.ObjectList { return &autoscaling_v1.HorizontalPodAutoscalerList{} },
+}
+
+// clusterLists are the cluster scoped kinds of the objects rendered from the templates.
+var clusterLists = []func() client.ObjectList{
+	func() client.ObjectList { return &rbac_v1.ClusterRoleList{} },
+	func() client.ObjectList { return &rbac_v1.ClusterRoleBindingList{} },
+}
+
+//+kubebuilder:rbac:groups="",resources=configmaps;secrets;services;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;statefulsets;replicasets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings;clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete;escalate;bind
+
+// Kinds returns the kinds of the objects rendered from the templates.
+func Kinds() []client.Object {
+	return []client.Object{
+		&core_v1.ConfigMap{}, &core_v1.Secret{}, &core_v1.Service{}, &core_v1.ServiceAccount{},
+		&rbac_v1.Role{}, &rbac_v1.RoleBinding{}, &networking_v1.Ingress{},
+		&app_v1.Deployment{}, &app_v1.DaemonSet{}, &app_v1.StatefulSet{}, &app_v1.ReplicaSet{},
+		&batch_v1.Job{}, &batch_v1.CronJob{}, &autoscaling_v1.HorizontalPodAutoscaler{},
+		&rbac_v1.ClusterRole{}, &rbac_v1.ClusterRoleBinding{},
+	}
+}
+
+func (r *reconciler) Prune(instance client.Object, owner metav1.OwnerReference, selector labels.Selector) error {
+	return r.prune(context.Background(), instance, owner, selector, nil)
+}
+
+func (r *reconciler) prune(ctx context.Context, instance client.Object, owner metav1.OwnerReference, selector labels.Selector, inventory *util_client.Inventory) error {
+	prune := func(list client.ObjectList, namespace string) error {
+		pruned, err := util_client.PruneOwned(ctx, r.client, list, namespace, selector, owner.UID, inventory)
+		for _, obj := range pruned {
+			level.Info(r.logger).Log("msg", "pruned orphaned resource", "instance", owner.Name, "name", obj.GetName())
+			r.recordPruned(instance, obj)
+		}
+		return err
+	}
+
+	for _, newList := range namespacedLists {
+		if err := prune(newList(), instance.GetNamespace()); err != nil {
+			return err
+		}
+	}
+	for _, newList := range clusterLists {
+		if err := prune(newList(), ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderedObjects returns all the objects rendered in the manifests.
+func renderedObjects(manifests *manifest.AppManifests) []client.Object {
+	objects := commonObjects(&manifests.Manifests)
+	for _, comp := range manifests.CompsMenifests {
+		objects = append(objects, commonObjects(&comp.Manifests)...)
+		if comp.Deployment != nil {
+			objects = append(objects, comp.Deployment)
+		}
+		if comp.DaemonSet != nil {
+			objects = append(objects, comp.DaemonSet)
+		}
+		if comp.StatefulSet != nil {
+			objects = append(objects, comp.StatefulSet)
+		}
+		if comp.ReplicaSet != nil {
+			objects = append(objects, comp.ReplicaSet)
+		}
+		if comp.Job != nil {
+			objects = append(objects, comp.Job)
+		}
+		if comp.CronJob != nil {
+			objects = append(objects, comp.CronJob)
+		}
+		if comp.HPA != nil {
+			objects = append(objects, comp.HPA)
+		}
+	}
+	return objects
+}
+
+func commonObjects(manifests *manifest.Manifests) []client.Object {
+	var objects []client.Object
+	for _, cm := range manifests.ConfigMaps {
+		objects = append(objects, cm)
+	}
+	for _, secret := range manifests.Secrets {
+		objects = append(objects, secret)
+	}
+	for _, svc := range manifests.Services {
+		objects = append(objects, svc)
+	}
+	if manifests.ServiceAccount != nil {
+		objects = append(objects, manifests.ServiceAccount)
+	}
+	if manifests.ClusterRole != nil {
+		objects = append(objects, manifests.ClusterRole)
+	}
+	if manifests.ClusterRoleBinding != nil {
+		objects = append(objects, manifests.ClusterRoleBinding)
+	}
+	if manifests.Role != nil {
+		objects = append(objects, manifests.Role)
+	}
+	if manifests.RoleBinding != nil {
+		objects = append(objects, manifests.RoleBinding)
+	}
+	if manifests.Ingress != nil {
+		objects = append(objects, manifests.Ingress)
+	}
+	return objects
+}
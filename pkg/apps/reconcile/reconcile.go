@@ -5,49 +5,86 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/udmire/observability-operator/pkg/apps/manifest"
+	"github.com/udmire/observability-operator/pkg/utils"
 	util_client "github.com/udmire/observability-operator/pkg/utils/client"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 type AppReconciler interface {
-	Reconcile(owner metav1.OwnerReference, appType, name string, manifest *manifest.AppManifests) error
+	Reconcile(instance client.Object, owner metav1.OwnerReference, appType, name string, manifest *manifest.AppManifests) error
+	Prune(instance client.Object, owner metav1.OwnerReference, selector labels.Selector) error
 	CleanClusterLayerResources(uid types.UID, selector labels.Selector) error
+	SetEventRecorder(recorder record.EventRecorder)
 }
 
-func New(logger log.Logger, client client.Client) AppReconciler {
+func New(logger log.Logger, client client.Client, reg prometheus.Registerer) AppReconciler {
 	return &reconciler{
 		logger: logger,
 		client: client,
+
+		resourcesApplied: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "observperator",
+			Name:      "resources_applied_total",
+			Help:      "Total number of resources applied to the cluster, by group, version, kind and operation.",
+		}, []string{"group", "version", "resource_kind", "operation"}),
+		writesSkipped: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "observperator",
+			Name:      "resource_writes_skipped_total",
+			Help:      "Total number of resource writes skipped as the spec hash of the live resource matched.",
+		}, []string{"group", "version", "resource_kind"}),
 	}
 }
 
 type reconciler struct {
-	logger log.Logger
-	client client.Client
+	logger   log.Logger
+	client   client.Client
+	recorder record.EventRecorder
+
+	resourcesApplied *prometheus.CounterVec
+	writesSkipped    *prometheus.CounterVec
+}
+
+func (r *reconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.recorder = recorder
 }
 
-func (r *reconciler) Reconcile(owner metav1.OwnerReference, appType, name string, manifest *manifest.AppManifests) error {
+func (r *reconciler) Reconcile(instance client.Object, owner metav1.OwnerReference, appType, name string, manifest *manifest.AppManifests) error {
 	level.Info(r.logger).Log("msg", "start to reconcile", appType, name)
 	cxt := context.Background()
 
-	err := r.reconcile(cxt, owner, appType, name, &manifest.Manifests)
+	err := r.reconcile(cxt, instance, owner, appType, name, &manifest.Manifests)
 	if err != nil {
 		level.Warn(r.logger).Log("msg", "reconcile manifests failed", appType, name, "err", err)
 		return err
 	}
 
 	for _, component := range manifest.CompsMenifests {
-		err = r.reconcileComponent(cxt, owner, appType, name, component)
+		err = r.reconcileComponent(cxt, instance, owner, appType, name, component)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed", appType, name, "comp", component.Name, "err", err)
 			return err
 		}
 	}
 
+	inventory := util_client.NewInventory(r.client)
+	if err = inventory.Add(renderedObjects(manifest)...); err != nil {
+		level.Warn(r.logger).Log("msg", "failed to take the inventory of manifests", appType, name, "err", err)
+		return err
+	}
+	if err = r.prune(cxt, instance, owner, utils.InstanceSelector(name), inventory); err != nil {
+		level.Warn(r.logger).Log("msg", "prune orphaned resources failed", appType, name, "err", err)
+		return err
+	}
+
 	level.Info(r.logger).Log("msg", "reconcile success", appType, name)
 
 	return nil
@@ -68,160 +105,223 @@ func (r *reconciler) CleanClusterLayerResources(uid types.UID, selector labels.S
 	return nil
 }
 
-func (r *reconciler) reconcile(ctx context.Context, owner metav1.OwnerReference, appType, name string, manifest *manifest.Manifests) error {
+func (r *reconciler) reconcile(ctx context.Context, instance client.Object, owner metav1.OwnerReference, appType, name string, manifest *manifest.Manifests) error {
 	if manifest.ServiceAccount != nil {
 		manifest.ServiceAccount.OwnerReferences = append(manifest.ServiceAccount.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateServiceAccount(ctx, r.client, manifest.ServiceAccount)
+		result, err := util_client.Apply(ctx, r.client, manifest.ServiceAccount)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create sa", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.ServiceAccount, result)
 	}
 
 	if manifest.ClusterRole != nil {
 		manifest.ClusterRole.OwnerReferences = append(manifest.ClusterRole.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateClusterRole(ctx, r.client, manifest.ClusterRole)
+		result, err := util_client.Apply(ctx, r.client, manifest.ClusterRole)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create clusterRole", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.ClusterRole, result)
 	}
 
 	if manifest.ClusterRoleBinding != nil {
-		manifest.ClusterRole.OwnerReferences = append(manifest.ClusterRole.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateClusterRoleBinding(ctx, r.client, manifest.ClusterRoleBinding)
+		manifest.ClusterRoleBinding.OwnerReferences = append(manifest.ClusterRoleBinding.OwnerReferences, owner)
+		result, err := util_client.Apply(ctx, r.client, manifest.ClusterRoleBinding)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create clusterRoleBinding", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.ClusterRoleBinding, result)
 	}
 
 	if manifest.Role != nil {
 		manifest.Role.OwnerReferences = append(manifest.Role.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateRole(ctx, r.client, manifest.Role)
+		result, err := util_client.Apply(ctx, r.client, manifest.Role)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create role", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.Role, result)
 	}
 
 	if manifest.RoleBinding != nil {
 		manifest.RoleBinding.OwnerReferences = append(manifest.RoleBinding.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateRoleBinding(ctx, r.client, manifest.RoleBinding)
+		result, err := util_client.Apply(ctx, r.client, manifest.RoleBinding)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create roleBinding", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.RoleBinding, result)
 	}
 
 	if manifest.Ingress != nil {
 		manifest.Ingress.OwnerReferences = append(manifest.Ingress.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateIngress(ctx, r.client, manifest.Ingress)
+		result, err := util_client.Apply(ctx, r.client, manifest.Ingress)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create ingress", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.Ingress, result)
 	}
 
 	for _, secret := range manifest.Secrets {
 		secret.OwnerReferences = append(secret.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateSecret(ctx, r.client, secret)
+		result, err := util_client.Apply(ctx, r.client, secret)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create secret", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, secret, result)
 	}
 
 	for _, cm := range manifest.ConfigMaps {
 		cm.OwnerReferences = append(cm.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateConfigMap(ctx, r.client, cm)
+		result, err := util_client.Apply(ctx, r.client, cm)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create configmap", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, cm, result)
 	}
 
 	for _, svc := range manifest.Services {
 		svc.OwnerReferences = append(svc.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateService(ctx, r.client, svc)
+		result, err := util_client.Apply(ctx, r.client, svc)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create service", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, svc, result)
 	}
 
 	return nil
 }
 
-func (r *reconciler) reconcileComponent(ctx context.Context, owner metav1.OwnerReference, appType, name string, manifest *manifest.CompManifests) error {
-	err := r.reconcile(ctx, owner, appType, name, &manifest.Manifests)
+func (r *reconciler) reconcileComponent(ctx context.Context, instance client.Object, owner metav1.OwnerReference, appType, name string, manifest *manifest.CompManifests) error {
+	err := r.reconcile(ctx, instance, owner, appType, name, &manifest.Manifests)
 	if err != nil {
 		level.Warn(r.logger).Log("msg", "reconcile manifests failed to create component", appType, name, "component", manifest.Name, "err", err)
 		return err
 	}
 
+	if manifest.HPA != nil {
+		// The replicas are managed by the HPA, applying them would take the ownership back.
+		if manifest.Deployment != nil {
+			manifest.Deployment.Spec.Replicas = nil
+		}
+		if manifest.StatefulSet != nil {
+			manifest.StatefulSet.Spec.Replicas = nil
+		}
+		if manifest.ReplicaSet != nil {
+			manifest.ReplicaSet.Spec.Replicas = nil
+		}
+	}
+
 	if manifest.Deployment != nil {
 		manifest.Deployment.OwnerReferences = append(manifest.Deployment.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateDeployment(ctx, r.client, manifest.Deployment)
+		result, err := util_client.Apply(ctx, r.client, manifest.Deployment)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create deployment workload", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.Deployment, result)
 	}
 
 	if manifest.DaemonSet != nil {
 		manifest.DaemonSet.OwnerReferences = append(manifest.DaemonSet.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateDaemonSet(ctx, r.client, manifest.DaemonSet)
+		result, err := util_client.Apply(ctx, r.client, manifest.DaemonSet)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create daemonset workload", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.DaemonSet, result)
 	}
 
 	if manifest.StatefulSet != nil {
 		manifest.StatefulSet.OwnerReferences = append(manifest.StatefulSet.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateStatefulSet(ctx, r.client, manifest.StatefulSet)
+		result, err := util_client.Apply(ctx, r.client, manifest.StatefulSet)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create statefulset workload", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.StatefulSet, result)
 	}
 
 	if manifest.ReplicaSet != nil {
 		manifest.ReplicaSet.OwnerReferences = append(manifest.ReplicaSet.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateReplicaSet(ctx, r.client, manifest.ReplicaSet)
+		result, err := util_client.Apply(ctx, r.client, manifest.ReplicaSet)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create replicaset workload", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.ReplicaSet, result)
 	}
 
 	if manifest.Job != nil {
 		manifest.Job.OwnerReferences = append(manifest.Job.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateJob(ctx, r.client, manifest.Job)
+		result, err := util_client.Apply(ctx, r.client, manifest.Job)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create job workload", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.Job, result)
 	}
 
 	if manifest.CronJob != nil {
-		manifest.ClusterRole.OwnerReferences = append(manifest.ClusterRole.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateCronJob(ctx, r.client, manifest.CronJob)
+		manifest.CronJob.OwnerReferences = append(manifest.CronJob.OwnerReferences, owner)
+		result, err := util_client.Apply(ctx, r.client, manifest.CronJob)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create cronjob workload", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.CronJob, result)
 	}
 
 	if manifest.HPA != nil {
 		manifest.HPA.OwnerReferences = append(manifest.HPA.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateHPA(ctx, r.client, manifest.HPA)
+		result, err := util_client.Apply(ctx, r.client, manifest.HPA)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create hpa", appType, name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, manifest.HPA, result)
 	}
 
 	return nil
 }
+
+func (r *reconciler) recordApplied(instance client.Object, obj client.Object, result controllerutil.OperationResult) {
+	gvk, err := r.client.GroupVersionKindFor(obj)
+	if err != nil {
+		level.Warn(r.logger).Log("msg", "failed to resolve the kind of applied resource", "name", obj.GetName(), "err", err)
+	}
+	if result == util_client.OperationResultSkipped {
+		r.writesSkipped.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Inc()
+		return
+	}
+	r.resourcesApplied.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, string(result)).Inc()
+
+	if r.recorder == nil {
+		return
+	}
+	switch result {
+	case controllerutil.OperationResultCreated:
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, utils.EventReasonCreated, "Created %s %s", gvk.Kind, obj.GetName())
+	case controllerutil.OperationResultUpdated:
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, utils.EventReasonUpdated, "Updated %s %s", gvk.Kind, obj.GetName())
+	}
+}
+
+func (r *reconciler) recordPruned(instance client.Object, obj client.Object) {
+	gvk, err := r.client.GroupVersionKindFor(obj)
+	if err != nil {
+		level.Warn(r.logger).Log("msg", "failed to resolve the kind of pruned resource", "name", obj.GetName(), "err", err)
+	}
+	r.resourcesApplied.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, "pruned").Inc()
+
+	if r.recorder != nil {
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, utils.EventReasonPruned, "Pruned %s %s", gvk.Kind, obj.GetName())
+	}
+}
@@ -8,11 +8,15 @@ import (
 	rbac_v1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/udmire/observability-operator/api/v1alpha1"
 	"github.com/udmire/observability-operator/pkg/apps/manifest"
+	info "github.com/udmire/observability-operator/pkg/operator/providers"
 	"github.com/udmire/observability-operator/pkg/templates/provider"
 	"github.com/udmire/observability-operator/pkg/templates/template"
 	"github.com/udmire/observability-operator/pkg/utils"
@@ -24,38 +28,108 @@ type AppHandler interface {
 	Handle(app v1alpha1.AppSpec) (*manifest.AppManifests, error)
 	Selector(app v1alpha1.AppSpec) labels.Selector
 	Decorate(manifest *manifest.AppManifests, decorators ...Decorator)
+	Validate(app v1alpha1.AppSpec, fldPath *field.Path) field.ErrorList
+	SetClusterNameProvider(cnp info.StringProvider)
 }
 
 type appHandler struct {
 	logger log.Logger
 
 	provider provider.TemplateProvider
+	cnp      info.StringProvider
+
+	templateLookupMisses *prometheus.CounterVec
 }
 
-func New(provider provider.TemplateProvider, logger log.Logger) AppHandler {
+func New(provider provider.TemplateProvider, reg prometheus.Registerer, logger log.Logger) AppHandler {
 	return &appHandler{
 		logger:   logger,
 		provider: provider,
+
+		templateLookupMisses: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "observperator",
+			Name:      "template_lookup_misses_total",
+			Help:      "Total number of templates not found in the template store, by template and type of version constraint.",
+		}, []string{"template", "constraint"}),
 	}
 }
 
 func (h *appHandler) Handle(app v1alpha1.AppSpec) (*manifest.AppManifests, error) {
-	template := h.getTemplate(app.Template.Name, app.Template.Version)
-	if template == nil {
+	appTemplate := h.getTemplate(app.Template.Name, app.Template.Version)
+	if appTemplate == nil {
 		version := "latest"
 		if len(app.Template.Version) > 0 {
 			version = app.Template.Version
 		}
+		if err := h.templateError(app.Template.Name, app.Template.Version); err != nil {
+			level.Warn(h.logger).Log("msg", "template cannot be provided", "name", app.Template.Name, "version", version, "err", err)
+			return nil, fmt.Errorf("%s:%s: %w", app.Template.Name, version, err)
+		}
 		level.Warn(h.logger).Log("msg", "template not found", "name", app.Template.Name, "version", version)
-		return nil, fmt.Errorf("template %s:%s not found", app.Template.Name, version)
+		return nil, fmt.Errorf("%w: %s:%s", template.ErrTemplateNotFound, app.Template.Name, version)
 	}
 
-	manifest := manifest.NewTemplateBuilder(template).Build()
+	values, errs := h.values(app, appTemplate, field.NewPath("values"))
+	if len(errs) > 0 {
+		level.Warn(h.logger).Log("msg", "invalid values", "name", app.Name, "err", errs.ToAggregate())
+		return nil, fmt.Errorf("%w: %v", template.ErrInvalidValues, errs.ToAggregate())
+	}
+
+	manifest, err := manifest.NewTemplateBuilder(appTemplate, values).Build()
+	if err != nil {
+		level.Warn(h.logger).Log("msg", "failed to build manifests", "name", appTemplate.Name, "version", appTemplate.Version, "err", err)
+		return nil, err
+	}
+	manifest.TemplateName, manifest.TemplateVersion = appTemplate.Name, appTemplate.Version
+	manifest.TemplateRevision = appTemplate.Revision
 	h.updateImagesWithRegistry(app.Registry, manifest)
+	// the objects are labeled with the resolved version rather than the version constraint.
+	app.Template.Version = appTemplate.Version
 	return h.customerizeApp(manifest, app)
 }
 
+func (h *appHandler) SetClusterNameProvider(cnp info.StringProvider) {
+	h.cnp = cnp
+}
+
+// Validate validates the values of the app against the parameters and the schema of its template.
+// Apps referencing templates missing from the store are not rejected, the templates may be
+// synchronized later.
+func (h *appHandler) Validate(app v1alpha1.AppSpec, fldPath *field.Path) field.ErrorList {
+	appTemplate := h.lookupTemplate(app.Template.Name, app.Template.Version)
+	if appTemplate == nil {
+		return nil
+	}
+	_, errs := h.values(app, appTemplate, fldPath.Child("values"))
+	return errs
+}
+
+// values returns the values the template files of the app are rendered against.
+func (h *appHandler) values(app v1alpha1.AppSpec, appTemplate *template.AppTemplate, fldPath *field.Path) (*template.Values, field.ErrorList) {
+	var raw []byte
+	if app.Values != nil {
+		raw = app.Values.Raw
+	}
+	merged, errs := appTemplate.MergeValues(raw, fldPath)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	values := &template.Values{
+		Values:   merged,
+		Instance: template.InstanceInfo{Name: app.Name, Namespace: app.Namespace},
+		Template: template.TemplateInfo{Name: appTemplate.Name, Version: appTemplate.Version},
+	}
+	if h.cnp != nil {
+		values.Cluster.Name = h.cnp()
+	}
+	return values, nil
+}
+
 func (h *appHandler) Selector(app v1alpha1.AppSpec) labels.Selector {
+	if appTemplate := h.lookupTemplate(app.Template.Name, app.Template.Version); appTemplate != nil {
+		app.Template.Version = appTemplate.Version
+	}
 	instanceLabels := utils.AppInstanceLabels(app.Name, app.Template.Name, app.Template.Version)
 	delete(instanceLabels, utils.AppLabel)
 	return labels.SelectorFromSet(labels.Set(instanceLabels))
@@ -91,7 +165,7 @@ func (h *appHandler) customerizeApp(manifest *manifest.AppManifests, app v1alpha
 	return manifest, nil
 }
 
-func (h *appHandler) customerizeComponent(manifest *manifest.CompManifests, spec v1alpha1.ComponentSpec, template v1alpha1.Template, prefix, instance, component, namespace string) error {
+func (h *appHandler) customerizeComponent(manifest *manifest.CompManifests, spec v1alpha1.ComponentSpec, template v1alpha1.TemplateReference, prefix, instance, component, namespace string) error {
 	compLabels := utils.ComponentLabels(instance, template.Name, template.Version, component)
 
 	h.customerize(&manifest.Manifests, spec.CommonSpec, prefix, component, namespace, compLabels)
@@ -119,17 +193,29 @@ func (h *appHandler) customerizeComponent(manifest *manifest.CompManifests, spec
 	return err
 }
 
-func (h *appHandler) getTemplate(name, version string) *template.AppTemplate {
-	var template *template.AppTemplate
-	if len(version) == 0 {
-		template = h.provider.GetLatestTemplate(name)
-	} else {
-		template = h.provider.GetTemplate(name, version)
+// templateError returns why the template resolved for the version constraint cannot be provided,
+// e.g. the template cannot be composed with its base, nil if it is not found.
+func (h *appHandler) templateError(name, version string) error {
+	if resolver, ok := h.provider.(provider.TemplateErrorProvider); ok {
+		return resolver.TemplateError(name, version)
 	}
+	return nil
+}
 
+func (h *appHandler) getTemplate(name, version string) *template.AppTemplate {
+	template := h.lookupTemplate(name, version)
+	if template == nil {
+		h.templateLookupMisses.WithLabelValues(name, utils.ConstraintType(version)).Inc()
+	}
 	return template
 }
 
+// lookupTemplate resolves the version constraint against the versions of the template in the
+// store, an empty constraint resolves to the latest version.
+func (h *appHandler) lookupTemplate(name, version string) *template.AppTemplate {
+	return h.provider.ResolveTemplate(name, version)
+}
+
 func (h *appHandler) customerize(manifest *manifest.Manifests, app v1alpha1.CommonSpec, prefix, name, namespace string, labels map[string]string) error {
 	configMapsCustom(manifest, app.ConfigMaps, prefix, namespace, labels)
 	secretsCustom(manifest, app.Secrets, prefix, namespace, labels)
@@ -6,6 +6,7 @@ import (
 
 	core_v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/yaml"
 )
 
 func MergePatchContainers(base, patches []core_v1.Container) ([]core_v1.Container, error) {
@@ -73,3 +74,22 @@ func MergePatch[T any, I func(T) string, N func() T](base, patches []T, identifi
 
 	return out, nil
 }
+
+// MergePatchManifest strategic merge patches the YAML manifest with the YAML patch, the schema
+// being the empty resource of the manifest.
+func MergePatchManifest(base, patch []byte, schema interface{}) ([]byte, error) {
+	baseBytes, err := yaml.YAMLToJSON(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert base manifest to json: %w", err)
+	}
+	patchBytes, err := yaml.YAMLToJSON(patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert patch manifest to json: %w", err)
+	}
+
+	jsonResult, err := strategicpatch.StrategicMergePatch(baseBytes, patchBytes, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate merge patch: %w", err)
+	}
+	return yaml.JSONToYAML(jsonResult)
+}
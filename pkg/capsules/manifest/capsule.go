@@ -33,6 +33,12 @@ type Manifest struct {
 type CapsuleManifests struct {
 	Manifest
 
+	// TemplateName and TemplateVersion identify the template the manifests are rendered from,
+	// TemplateRevision is the source revision of the template if known.
+	TemplateName     string
+	TemplateVersion  string
+	TemplateRevision string
+
 	CompsManifests []*CompManifests
 }
 
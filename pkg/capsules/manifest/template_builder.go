@@ -1,7 +1,9 @@
 package manifest
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 
 	core_v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -12,65 +14,87 @@ import (
 )
 
 type Builder interface {
-	Build() *CapsuleManifests
+	Build() (*CapsuleManifests, error)
 }
 
-func New(template *template.AppTemplate) Builder {
+// New returns the builder of the manifests of the capsule template, the capsule files are
+// rendered against the values.
+func New(template *template.AppTemplate, values *template.Values) Builder {
 	if template == nil {
 		return nil
 	}
 	return &templateBuilder{
 		template: template,
+		values:   values,
 	}
 }
 
 type templateBuilder struct {
 	template *template.AppTemplate
+	values   *template.Values
 }
 
-func (b *templateBuilder) Build() *CapsuleManifests {
+func (b *templateBuilder) Build() (*CapsuleManifests, error) {
 	cms := &CapsuleManifests{}
 	var capsule []*Capsule
 	files := make(map[string][]byte)
 	for _, tempFile := range b.template.TemplateFiles {
 		if tempFile.FileName == CapsuleFile {
-			capsule = b.buildCapsules(tempFile.Content)
+			var err error
+			if capsule, err = b.buildCapsules(tempFile.Content); err != nil {
+				return nil, err
+			}
 			continue
 		}
-		files[tempFile.FileName] = tempFile.Content
+		content, err := tempFile.Render(b.values)
+		if err != nil {
+			return nil, err
+		}
+		files[itemName(tempFile)] = content
 	}
 	appLabels := appLabels(b.template.Name)
 	cms.Manifest = *b.buildManifests(appLabels, capsule, files)
 
 	for _, comp := range b.template.Workloads {
-		cms.CompsManifests = append(cms.CompsManifests, b.buildComp(b.template.Name, comp))
+		compManifests, err := b.buildComp(b.template.Name, comp)
+		if err != nil {
+			return nil, err
+		}
+		cms.CompsManifests = append(cms.CompsManifests, compManifests)
 	}
 
-	return cms
+	return cms, nil
 }
 
-func (b *templateBuilder) buildComp(app string, template *template.WorkloadTemplate) *CompManifests {
+func (b *templateBuilder) buildComp(app string, template *template.WorkloadTemplate) (*CompManifests, error) {
 	compLabels := componentLabels(app, template.Name)
 
 	var capsules []*Capsule
 	files := make(map[string][]byte)
 	for _, tempFile := range b.template.TemplateFiles {
 		if tempFile.FileName == CapsuleFile {
-			capsules = b.buildCapsules(tempFile.Content)
+			var err error
+			if capsules, err = b.buildCapsules(tempFile.Content); err != nil {
+				return nil, err
+			}
 			continue
 		}
-		files[tempFile.FileName] = tempFile.Content
+		content, err := tempFile.Render(b.values)
+		if err != nil {
+			return nil, err
+		}
+		files[itemName(tempFile)] = content
 	}
 
 	manifests := b.buildManifests(compLabels, capsules, files)
 	return &CompManifests{
 		Manifest: *manifests,
-	}
+	}, nil
 }
 
 func (b *templateBuilder) buildManifests(labels map[string]string, capsules []*Capsule, refs map[string][]byte) *Manifest {
 	if len(capsules) < 1 {
-		return nil
+		return &Manifest{}
 	}
 
 	manifests := &Manifest{}
@@ -120,13 +144,13 @@ func (b *templateBuilder) buildBinaryDatas(cap *Capsule, refs map[string][]byte)
 	return result
 }
 
-func (b *templateBuilder) buildCapsules(content []byte) []*Capsule {
+func (b *templateBuilder) buildCapsules(content []byte) ([]*Capsule, error) {
 	cap := []*Capsule{}
 	err := yaml.Unmarshal(content, &cap)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("%w: cannot decode %s: %v", template.ErrInvalidTemplate, CapsuleFile, err)
 	}
-	return cap
+	return cap, nil
 }
 
 // func (b *templateBuilder) BuildComp(template *template.WorkloadTemplate) *CompManifests {
@@ -169,6 +193,11 @@ func (b *templateBuilder) buildCapsules(content []byte) []*Capsule {
 //		return -1, ""
 //	}
 
+// itemName returns the name the capsule items refer the file by, without the rendered file suffix.
+func itemName(file *template.TemplateFile) string {
+	return strings.TrimSuffix(file.FileName, template.RenderedFileSuffix)
+}
+
 func appLabels(app string) map[string]string {
 	return map[string]string{
 		utils.AppLabel: app,
@@ -41,7 +41,12 @@ func Test_templateBuilder_buildCapsules(t *testing.T) {
 			b := &templateBuilder{
 				template: tt.fields.template,
 			}
-			if got := b.buildCapsules(tt.args.content); !reflect.DeepEqual(len(got), tt.want) {
+			got, err := b.buildCapsules(tt.args.content)
+			if err != nil {
+				t.Errorf("templateBuilder.buildCapsules() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(len(got), tt.want) {
 				t.Errorf("templateBuilder.buildCapsules() = %v, want %v", got, tt.want)
 			}
 		})
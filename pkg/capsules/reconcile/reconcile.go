@@ -5,46 +5,136 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/udmire/observability-operator/pkg/capsules/manifest"
+	"github.com/udmire/observability-operator/pkg/utils"
 	util_client "github.com/udmire/observability-operator/pkg/utils/client"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// Kinds returns the kinds of the objects rendered from the capsule templates.
+func Kinds() []client.Object {
+	return []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}}
+}
+
 type CapsuleReconciler interface {
-	Reconcile(ctx context.Context, owner metav1.OwnerReference, manifest *manifest.CapsuleManifests) error
+	Reconcile(ctx context.Context, instance client.Object, owner metav1.OwnerReference, manifest *manifest.CapsuleManifests) error
+	SetEventRecorder(recorder record.EventRecorder)
 }
 
 type capsuleReconciler struct {
-	logger log.Logger
-	client client.Client
+	logger   log.Logger
+	client   client.Client
+	recorder record.EventRecorder
+
+	resourcesApplied *prometheus.CounterVec
+	writesSkipped    *prometheus.CounterVec
 }
 
-func New(logger log.Logger, client client.Client) CapsuleReconciler {
+func New(logger log.Logger, client client.Client, reg prometheus.Registerer) CapsuleReconciler {
 	return &capsuleReconciler{
 		logger: logger,
 		client: client,
+
+		resourcesApplied: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "observperator",
+			Name:      "resources_applied_total",
+			Help:      "Total number of resources applied to the cluster, by group, version, kind and operation.",
+		}, []string{"group", "version", "resource_kind", "operation"}),
+		writesSkipped: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "observperator",
+			Name:      "resource_writes_skipped_total",
+			Help:      "Total number of resource writes skipped as the spec hash of the live resource matched.",
+		}, []string{"group", "version", "resource_kind"}),
 	}
 }
 
-func (r *capsuleReconciler) Reconcile(ctx context.Context, owner metav1.OwnerReference, manifest *manifest.CapsuleManifests) error {
+func (r *capsuleReconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.recorder = recorder
+}
+
+func (r *capsuleReconciler) Reconcile(ctx context.Context, instance client.Object, owner metav1.OwnerReference, manifest *manifest.CapsuleManifests) error {
 	for _, secret := range manifest.Secrets {
 		secret.OwnerReferences = append(secret.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateSecret(ctx, r.client, secret)
+		result, err := util_client.Apply(ctx, r.client, secret)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create secret", "name", secret.Name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, secret, result)
 	}
 
 	for _, cm := range manifest.ConfigMaps {
 		cm.OwnerReferences = append(cm.OwnerReferences, owner)
-		err := util_client.CreateOrUpdateConfigMap(ctx, r.client, cm)
+		result, err := util_client.Apply(ctx, r.client, cm)
 		if err != nil {
 			level.Warn(r.logger).Log("msg", "reconcile manifests failed to create configmap", "name", cm.Name, "err", err)
 			return err
 		}
+		r.recordApplied(instance, cm, result)
+	}
+
+	inventory := util_client.NewInventory(r.client)
+	for _, secret := range manifest.Secrets {
+		if err := inventory.Add(secret); err != nil {
+			return err
+		}
+	}
+	for _, cm := range manifest.ConfigMaps {
+		if err := inventory.Add(cm); err != nil {
+			return err
+		}
+	}
+	for _, list := range []client.ObjectList{&corev1.SecretList{}, &corev1.ConfigMapList{}} {
+		pruned, err := util_client.PruneOwned(ctx, r.client, list, instance.GetNamespace(), utils.InstanceSelector(instance.GetName()), owner.UID, inventory)
+		for _, obj := range pruned {
+			level.Info(r.logger).Log("msg", "pruned orphaned resource", "capsule", instance.GetName(), "name", obj.GetName())
+			r.recordPruned(instance, obj)
+		}
+		if err != nil {
+			level.Warn(r.logger).Log("msg", "prune orphaned resources failed", "capsule", instance.GetName(), "err", err)
+			return err
+		}
 	}
 
 	return nil
 }
+
+func (r *capsuleReconciler) recordApplied(instance client.Object, obj client.Object, result controllerutil.OperationResult) {
+	gvk, err := r.client.GroupVersionKindFor(obj)
+	if err != nil {
+		level.Warn(r.logger).Log("msg", "failed to resolve the kind of applied resource", "name", obj.GetName(), "err", err)
+	}
+	if result == util_client.OperationResultSkipped {
+		r.writesSkipped.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Inc()
+		return
+	}
+	r.resourcesApplied.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, string(result)).Inc()
+
+	if r.recorder == nil {
+		return
+	}
+	switch result {
+	case controllerutil.OperationResultCreated:
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, utils.EventReasonCreated, "Created %s %s", gvk.Kind, obj.GetName())
+	case controllerutil.OperationResultUpdated:
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, utils.EventReasonUpdated, "Updated %s %s", gvk.Kind, obj.GetName())
+	}
+}
+
+func (r *capsuleReconciler) recordPruned(instance client.Object, obj client.Object) {
+	gvk, err := r.client.GroupVersionKindFor(obj)
+	if err != nil {
+		level.Warn(r.logger).Log("msg", "failed to resolve the kind of pruned resource", "name", obj.GetName(), "err", err)
+	}
+	r.resourcesApplied.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind, "pruned").Inc()
+
+	if r.recorder != nil {
+		r.recorder.Eventf(instance, corev1.EventTypeNormal, utils.EventReasonPruned, "Pruned %s %s", gvk.Kind, obj.GetName())
+	}
+}
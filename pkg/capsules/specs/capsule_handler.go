@@ -5,11 +5,15 @@ import (
 
 	core_v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/udmire/observability-operator/api/v1alpha1"
 	"github.com/udmire/observability-operator/pkg/capsules/manifest"
+	info "github.com/udmire/observability-operator/pkg/operator/providers"
 	"github.com/udmire/observability-operator/pkg/templates/provider"
 	"github.com/udmire/observability-operator/pkg/templates/template"
 	"github.com/udmire/observability-operator/pkg/utils"
@@ -17,44 +21,122 @@ import (
 
 type CapsuleHandler interface {
 	Handle(app v1alpha1.CapsuleSpec) (*manifest.CapsuleManifests, error)
+	Validate(capsule v1alpha1.CapsuleSpec, fldPath *field.Path) field.ErrorList
+	SetClusterNameProvider(cnp info.StringProvider)
 }
 
 type capsuleHandler struct {
 	logger log.Logger
 
 	provider provider.TemplateProvider
+	cnp      info.StringProvider
+
+	templateLookupMisses *prometheus.CounterVec
 }
 
-func New(provider provider.TemplateProvider, logger log.Logger) CapsuleHandler {
+func New(provider provider.TemplateProvider, reg prometheus.Registerer, logger log.Logger) CapsuleHandler {
 	return &capsuleHandler{
 		logger:   logger,
 		provider: provider,
+
+		templateLookupMisses: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "observperator",
+			Name:      "template_lookup_misses_total",
+			Help:      "Total number of templates not found in the template store, by template and type of version constraint.",
+		}, []string{"template", "constraint"}),
 	}
 }
 
 func (h *capsuleHandler) Handle(capsule v1alpha1.CapsuleSpec) (*manifest.CapsuleManifests, error) {
-	template := h.getTemplate(capsule.Template.Name, capsule.Template.Version)
-	if template == nil {
+	capsuleTemplate := h.getTemplate(capsule.Template.Name, capsule.Template.Version)
+	if capsuleTemplate == nil {
 		version := "latest"
 		if len(capsule.Template.Version) > 0 {
 			version = capsule.Template.Version
 		}
+		if err := h.templateError(capsule.Template.Name, capsule.Template.Version); err != nil {
+			level.Warn(h.logger).Log("msg", "template cannot be provided", "name", capsule.Template.Name, "version", version, "err", err)
+			return nil, fmt.Errorf("%s:%s: %w", capsule.Template.Name, version, err)
+		}
 		level.Warn(h.logger).Log("msg", "template not found", "name", capsule.Template.Name, "version", version)
-		return nil, fmt.Errorf("template %s:%s not found", capsule.Template.Name, version)
+		return nil, fmt.Errorf("%w: %s:%s", template.ErrTemplateNotFound, capsule.Template.Name, version)
 	}
 
-	manifest := manifest.New(template).Build()
+	values, errs := h.values(capsule, capsuleTemplate, field.NewPath("values"))
+	if len(errs) > 0 {
+		level.Warn(h.logger).Log("msg", "invalid values", "name", capsule.Name, "err", errs.ToAggregate())
+		return nil, fmt.Errorf("%w: %v", template.ErrInvalidValues, errs.ToAggregate())
+	}
+
+	manifest, err := manifest.New(capsuleTemplate, values).Build()
+	if err != nil {
+		level.Warn(h.logger).Log("msg", "failed to build manifests", "name", capsuleTemplate.Name, "version", capsuleTemplate.Version, "err", err)
+		return nil, err
+	}
+	manifest.TemplateName, manifest.TemplateVersion = capsuleTemplate.Name, capsuleTemplate.Version
+	manifest.TemplateRevision = capsuleTemplate.Revision
+	// the objects are labeled with the resolved version rather than the version constraint.
+	capsule.Template.Version = capsuleTemplate.Version
 	return h.customerizeApp(manifest, capsule)
 }
 
-func (h *capsuleHandler) getTemplate(name, version string) *template.AppTemplate {
-	var template *template.AppTemplate
-	if len(version) == 0 {
-		template = h.provider.GetLatestTemplate(name)
-	} else {
-		template = h.provider.GetTemplate(name, version)
+func (h *capsuleHandler) SetClusterNameProvider(cnp info.StringProvider) {
+	h.cnp = cnp
+}
+
+// Validate validates the values of the capsule against the parameters and the schema of its
+// template. Capsules referencing templates missing from the store are not rejected.
+func (h *capsuleHandler) Validate(capsule v1alpha1.CapsuleSpec, fldPath *field.Path) field.ErrorList {
+	capsuleTemplate := h.lookupTemplate(capsule.Template.Name, capsule.Template.Version)
+	if capsuleTemplate == nil {
+		return nil
+	}
+	_, errs := h.values(capsule, capsuleTemplate, fldPath.Child("values"))
+	return errs
+}
+
+// values returns the values the capsule files are rendered against.
+func (h *capsuleHandler) values(capsule v1alpha1.CapsuleSpec, capsuleTemplate *template.AppTemplate, fldPath *field.Path) (*template.Values, field.ErrorList) {
+	var raw []byte
+	if capsule.Values != nil {
+		raw = capsule.Values.Raw
+	}
+	merged, errs := capsuleTemplate.MergeValues(raw, fldPath)
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
+	values := &template.Values{
+		Values:   merged,
+		Instance: template.InstanceInfo{Name: capsule.Name, Namespace: capsule.Namespace},
+		Template: template.TemplateInfo{Name: capsuleTemplate.Name, Version: capsuleTemplate.Version},
+	}
+	if h.cnp != nil {
+		values.Cluster.Name = h.cnp()
+	}
+	return values, nil
+}
+
+// lookupTemplate resolves the version constraint against the versions of the template in the
+// store, an empty constraint resolves to the latest version.
+func (h *capsuleHandler) lookupTemplate(name, version string) *template.AppTemplate {
+	return h.provider.ResolveTemplate(name, version)
+}
+
+// templateError returns why the template resolved for the version constraint cannot be provided,
+// e.g. the template cannot be composed with its base, nil if it is not found.
+func (h *capsuleHandler) templateError(name, version string) error {
+	if resolver, ok := h.provider.(provider.TemplateErrorProvider); ok {
+		return resolver.TemplateError(name, version)
+	}
+	return nil
+}
+
+func (h *capsuleHandler) getTemplate(name, version string) *template.AppTemplate {
+	template := h.lookupTemplate(name, version)
+	if template == nil {
+		h.templateLookupMisses.WithLabelValues(name, utils.ConstraintType(version)).Inc()
+	}
 	return template
 }
 
@@ -86,7 +168,7 @@ func (h *capsuleHandler) customerizeApp(manifest *manifest.CapsuleManifests, app
 	return manifest, nil
 }
 
-func (h *capsuleHandler) customerizeComponent(manifest *manifest.CompManifests, spec v1alpha1.CapsuleCommonSpec, template v1alpha1.Template, prefix, instance, component, namespace string) error {
+func (h *capsuleHandler) customerizeComponent(manifest *manifest.CompManifests, spec v1alpha1.CapsuleCommonSpec, template v1alpha1.TemplateReference, prefix, instance, component, namespace string) error {
 	compLabels := utils.ComponentLabels(instance, template.Name, template.Version, component)
 
 	return h.customerize(&manifest.Manifest, spec, prefix, component, namespace, compLabels)
@@ -18,14 +18,17 @@ package agents
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,6 +40,7 @@ import (
 	"github.com/udmire/observability-operator/pkg/operator/base"
 	info "github.com/udmire/observability-operator/pkg/operator/providers"
 	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/utils"
 )
 
 // AgentsReconciler reconciles a Agents object
@@ -45,25 +49,37 @@ type AgentsReconciler struct {
 
 	mgr ctrl.Manager
 	cnp info.StringProvider
+	tp  provider.TemplateProvider
 
 	handler       specs.AppHandler
 	appReconciler reconcile.AppReconciler
 }
 
-func New(client client.Client, schema *runtime.Scheme, tp provider.TemplateProvider, logger log.Logger) *AgentsReconciler {
+func New(client client.Client, schema *runtime.Scheme, tp provider.TemplateProvider, reg prometheus.Registerer, logger log.Logger) *AgentsReconciler {
+	reg = prometheus.WrapRegistererWith(prometheus.Labels{"kind": "Agents"}, reg)
 	reconciler := &AgentsReconciler{
 		BaseReconciler: base.BaseReconciler{
 			Client: client,
 			Scheme: schema,
 			Logger: logger,
+
+			Metrics: base.NewMetrics(reg),
 		},
 
-		handler:       specs.New(tp, logger),
-		appReconciler: reconcile.New(logger, client),
+		tp:            tp,
+		handler:       specs.New(tp, reg, logger),
+		appReconciler: reconcile.New(logger, client, reg),
 	}
 	reconciler.BasicService = services.NewIdleService(func(serviceContext context.Context) error {
-		return reconciler.SetupWithManager(reconciler.mgr)
+		if err := reconciler.SetupWithManager(reconciler.mgr); err != nil {
+			return err
+		}
+		if !reconciler.Webhooks {
+			return nil
+		}
+		return reconciler.SetupWebhookWithManager(reconciler.mgr)
 	}, nil)
+	reg.MustRegister(base.NewInstancesCollector(reconciler.countInstances, logger))
 	return reconciler
 }
 
@@ -73,6 +89,12 @@ func (r *AgentsReconciler) SetManager(mgr ctrl.Manager) {
 
 func (r *AgentsReconciler) SetClusterNameProvider(cnp info.StringProvider) {
 	r.cnp = cnp
+	r.handler.SetClusterNameProvider(cnp)
+}
+
+func (r *AgentsReconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.BaseReconciler.SetEventRecorder(recorder)
+	r.appReconciler.SetEventRecorder(recorder)
 }
 
 //+kubebuilder:rbac:groups=udmire.cn,resources=agents,verbs=get;list;watch;create;update;patch;delete
@@ -91,6 +113,7 @@ func (r *AgentsReconciler) SetClusterNameProvider(cnp info.StringProvider) {
 func (r *AgentsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	level.Info(r.Logger).Log("msg", "reconciling agent")
 	defer level.Info(r.Logger).Log("msg", "done reconciling agent")
+	start := time.Now()
 
 	instance := &v1alpha1.Agents{}
 	if err := r.Get(ctx, req.NamespacedName, instance); apierrors.IsNotFound(err) {
@@ -101,6 +124,8 @@ func (r *AgentsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, nil
 	}
 
+	defer r.Metrics.ObserveReconcile(req.Namespace, req.Name, instance.Spec.Template.Name, start)
+
 	r.normalizeInstance(instance)
 
 	finalizerName := "agents.udmire.cn/finalizer"
@@ -118,6 +143,7 @@ func (r *AgentsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			if err := r.appReconciler.CleanClusterLayerResources(instance.UID, selector); err != nil {
 				return ctrl.Result{}, err
 			}
+			r.Eventf(instance, v1.EventTypeNormal, utils.EventReasonCleanedUp, "Cleaned up cluster layer resources")
 
 			// remove our finalizer from the list and update it.
 			controllerutil.RemoveFinalizer(instance, finalizerName)
@@ -139,36 +165,68 @@ func (r *AgentsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		UID:                instance.UID,
 	}
 
+	err := r.reconcileAgent(owner, instance)
+	if err != nil {
+		r.Metrics.ReconcileFailed(instance.Namespace, instance.Name, instance.Spec.Template.Name)
+	}
+	base.MarkReady(&instance.Status.CommonStatus, instance.Generation,
+		v1alpha1.ConditionTemplateResolved, v1alpha1.ConditionDependenciesReady, v1alpha1.ConditionApplied)
+	if statusErr := r.UpdateStatus(ctx, instance); err == nil {
+		err = statusErr
+	}
+
+	return ctrl.Result{}, err
+}
+
+func (r *AgentsReconciler) reconcileAgent(owner metav1.OwnerReference, instance *v1alpha1.Agents) error {
+	status := &instance.Status.CommonStatus
+
 	manifest, err := r.handler.Handle(instance.Spec.AppSpec)
+	base.MarkCondition(status, instance.Generation, v1alpha1.ConditionTemplateResolved, v1alpha1.ReasonResolveFailed, err)
 	if err != nil {
 		level.Error(r.Logger).Log("msg", "failed to generate manifests", "instance", instance.Name, "err", err)
-		return ctrl.Result{}, err
+		r.EventResolveFailed(instance, instance.Name, err)
+		base.MarkBlocked(status, instance.Generation, v1alpha1.ConditionTemplateResolved, v1alpha1.ConditionDependenciesReady, v1alpha1.ConditionApplied)
+		return err
 	}
+	instance.Status.Template = v1alpha1.TemplateReference{Name: manifest.TemplateName, Version: manifest.TemplateVersion}
+	instance.Status.Revision = manifest.TemplateRevision
 
-	err = r.ProcessDependencies(owner, instance.Namespace, instance.Spec.Template, instance.Spec.Singleton, instance.Spec.Dependencies)
+	err = r.ProcessDependencies(instance, owner, instance.Name, instance.Spec.Template, instance.Spec.Singleton, instance.Spec.Dependencies)
+	base.MarkCondition(status, instance.Generation, v1alpha1.ConditionDependenciesReady, v1alpha1.ReasonDependencyFailed, err)
 	if err != nil {
 		level.Error(r.Logger).Log("msg", "failed to create dependencies", "instance", instance.Name, "err", err)
-		return ctrl.Result{}, err
+		r.Eventf(instance, v1.EventTypeWarning, utils.EventReasonDependencyFailed, "%s: %v", instance.Name, err)
+		base.MarkBlocked(status, instance.Generation, v1alpha1.ConditionDependenciesReady, v1alpha1.ConditionApplied)
+		return err
 	}
 
 	r.handler.Decorate(manifest, specs.ClusterNameEnvDecorator(r.cnp))
 
-	err = r.appReconciler.Reconcile(owner, "agents", instance.Name, manifest)
+	err = r.appReconciler.Reconcile(instance, owner, "agents", instance.Name, manifest)
+	base.MarkCondition(status, instance.Generation, v1alpha1.ConditionApplied, v1alpha1.ReasonApplyFailed, err)
 	if err != nil {
 		level.Error(r.Logger).Log("msg", "failed to apply manifests", "instance", instance.Name, "err", err)
-		return ctrl.Result{}, err
+		r.Eventf(instance, v1.EventTypeWarning, utils.EventReasonApplyFailed, "%s: %v", instance.Name, err)
+		return err
 	}
 
-	return ctrl.Result{}, nil
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AgentsReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	blder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Agents{}).
 		Owns(&v1alpha1.Exporters{}).
 		Owns(&v1alpha1.Apps{}).
-		Owns(&v1alpha1.Capsule{}).
+		Owns(&v1alpha1.Capsule{})
+	references := func(instance client.Object, name string) bool {
+		return instance.(*v1alpha1.Agents).Spec.Template.Name == name
+	}
+	newList := func() client.ObjectList { return &v1alpha1.AgentsList{} }
+	blder = base.WatchChildren(blder, r.Client, reconcile.Kinds(), newList, base.MatchName)
+	return base.WatchTemplates(blder, r.Client, r.tp, newList, references, r.Logger).
 		Complete(r)
 }
 
@@ -214,3 +272,19 @@ func (r *AgentsReconciler) updatePodTemplateEnv(spec *v1alpha1.PodTemplateSpec)
 		})
 	}
 }
+
+// countInstances counts the agents by the templates resolved for them.
+func (r *AgentsReconciler) countInstances(ctx context.Context) (map[v1alpha1.TemplateReference]int, error) {
+	list := &v1alpha1.AgentsList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[v1alpha1.TemplateReference]int)
+	for _, instance := range list.Items {
+		if len(instance.Status.Template.Name) > 0 {
+			counts[instance.Status.Template]++
+		}
+	}
+	return counts, nil
+}
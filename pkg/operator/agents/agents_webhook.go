@@ -0,0 +1,24 @@
+package agents
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/operator/base"
+)
+
+//+kubebuilder:webhook:path=/validate-udmire-cn-v1alpha1-agents,mutating=false,failurePolicy=fail,sideEffects=None,groups=udmire.cn,resources=agents,verbs=create;update,versions=v1alpha1,name=vagents.udmire.cn,admissionReviewVersions=v1
+
+// SetupWebhookWithManager sets up the webhook validating the values of the agents.
+func (r *AgentsReconciler) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Agents{}).
+		WithValidator(base.NewValidator("Agents", r.validate)).
+		Complete()
+}
+
+func (r *AgentsReconciler) validate(instance client.Object) field.ErrorList {
+	return r.handler.Validate(instance.(*v1alpha1.Agents).Spec.AppSpec, field.NewPath("spec"))
+}
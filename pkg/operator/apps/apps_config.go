@@ -1,11 +1,16 @@
 package apps
 
-import "flag"
+import (
+	"flag"
+	"time"
+)
 
 type Config struct {
-	Concurrency int
+	Concurrency    int           `yaml:"concurrency"`
+	ResyncInterval time.Duration `yaml:"resync_interval"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&c.Concurrency, "apps.concurrency", 3, "Max concurrent deploying apps.")
+	f.DurationVar(&c.ResyncInterval, "apps.resync-interval", 10*time.Minute, "Interval to reconcile the apps again even though nothing changed, 0 to disable.")
 }
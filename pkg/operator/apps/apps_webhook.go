@@ -0,0 +1,29 @@
+package apps
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/operator/base"
+)
+
+//+kubebuilder:webhook:path=/validate-udmire-cn-v1alpha1-apps,mutating=false,failurePolicy=fail,sideEffects=None,groups=udmire.cn,resources=apps,verbs=create;update,versions=v1alpha1,name=vapps.udmire.cn,admissionReviewVersions=v1
+
+// SetupWebhookWithManager sets up the webhook validating the values of the apployments.
+func (r *AppsReconciler) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Apps{}).
+		WithValidator(base.NewValidator("Apps", r.validate)).
+		Complete()
+}
+
+func (r *AppsReconciler) validate(instance client.Object) field.ErrorList {
+	var errs field.ErrorList
+	apployments := field.NewPath("spec", "apployments")
+	for name, app := range instance.(*v1alpha1.Apps).Spec.Apployments {
+		errs = append(errs, r.handler.Validate(app, apployments.Key(name))...)
+	}
+	return errs
+}
@@ -0,0 +1,77 @@
+package base
+
+import (
+	"sync"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApploymentTracker records the phases of the apployments reconciled concurrently.
+type ApploymentTracker struct {
+	mutex sync.Mutex
+
+	committed map[string]v1alpha1.ApploymentStatus
+	current   map[string]v1alpha1.ApploymentStatus
+}
+
+// NewApploymentTracker creates a tracker for the named apployments, starting from the
+// statuses persisted in the instance. Statuses of apployments not named are dropped.
+func NewApploymentTracker(persisted map[string]v1alpha1.ApploymentStatus, names []string) *ApploymentTracker {
+	tracker := &ApploymentTracker{
+		committed: make(map[string]v1alpha1.ApploymentStatus, len(names)),
+		current:   make(map[string]v1alpha1.ApploymentStatus, len(names)),
+	}
+	for _, name := range names {
+		status := persisted[name]
+		tracker.committed[name] = status
+		tracker.current[name] = status
+	}
+	return tracker
+}
+
+// Transit moves the apployment into the phase, the error is recorded when not nil.
+func (t *ApploymentTracker) Transit(name string, phase v1alpha1.ApploymentPhase, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	status := t.current[name]
+	status.Phase = phase
+	status.Error = ""
+	if err != nil {
+		status.Error = err.Error()
+	}
+	t.current[name] = status
+}
+
+// Resolve records the template resolved for the apployment, and the source revision of the
+// template if known.
+func (t *ApploymentTracker) Resolve(name, template, version, revision string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	status := t.current[name]
+	status.Template = v1alpha1.TemplateReference{Name: template, Version: version}
+	status.Revision = revision
+	t.current[name] = status
+}
+
+// Commit returns the statuses to be persisted. The transition time only moves when the
+// phase differs from the one committed last, so an unchanged apployment keeps its status.
+func (t *ApploymentTracker) Commit() map[string]v1alpha1.ApploymentStatus {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := metav1.Now()
+	result := make(map[string]v1alpha1.ApploymentStatus, len(t.current))
+	for name, status := range t.current {
+		if committed := t.committed[name]; committed.Phase == status.Phase && !committed.LastTransitionTime.IsZero() {
+			status.LastTransitionTime = committed.LastTransitionTime
+		} else {
+			status.LastTransitionTime = now
+		}
+		result[name] = status
+		t.committed[name] = status
+	}
+	return result
+}
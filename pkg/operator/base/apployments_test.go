@@ -0,0 +1,40 @@
+package base
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApploymentTracker_Commit(t *testing.T) {
+	since := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	persisted := map[string]v1alpha1.ApploymentStatus{
+		"node":    {Phase: v1alpha1.PhaseReady, LastTransitionTime: since},
+		"kube":    {Phase: v1alpha1.PhaseReady, LastTransitionTime: since},
+		"removed": {Phase: v1alpha1.PhaseReady, LastTransitionTime: since},
+	}
+
+	tracker := NewApploymentTracker(persisted, []string{"node", "kube", "added"})
+	tracker.Transit("node", v1alpha1.PhaseRendering, nil)
+	tracker.Resolve("node", "node-exporter", "1.5.0", "")
+	tracker.Transit("node", v1alpha1.PhaseReady, nil)
+	tracker.Transit("kube", v1alpha1.PhaseFailed, errors.New("forbidden"))
+	tracker.Transit("added", v1alpha1.PhaseReady, nil)
+
+	got := tracker.Commit()
+	if _, exists := got["removed"]; exists {
+		t.Errorf("Commit() kept the status of a removed apployment")
+	}
+	if node := got["node"]; !node.LastTransitionTime.Equal(&since) || node.Template.Version != "1.5.0" {
+		t.Errorf("Commit() node = %+v, want unchanged transition time and resolved version", node)
+	}
+	if kube := got["kube"]; kube.Phase != v1alpha1.PhaseFailed || kube.Error != "forbidden" || kube.LastTransitionTime.Equal(&since) {
+		t.Errorf("Commit() kube = %+v, want failed with a new transition time", kube)
+	}
+	if added := got["added"]; added.LastTransitionTime.IsZero() {
+		t.Errorf("Commit() added = %+v, want a transition time", added)
+	}
+}
@@ -2,31 +2,70 @@ package base
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/services"
 	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/templates/template"
 	"github.com/udmire/observability-operator/pkg/utils"
 	util_client "github.com/udmire/observability-operator/pkg/utils/client"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
 type BaseReconciler struct {
 	*services.BasicService
 
 	client.Client
-	Scheme *runtime.Scheme
-	Logger log.Logger
+	Scheme   *runtime.Scheme
+	Logger   log.Logger
+	Recorder record.EventRecorder
+	Metrics  *Metrics
+	Webhooks bool
+}
+
+func (r *BaseReconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.Recorder = recorder
+}
+
+// SetWebhooksEnabled enables the admission webhooks of the controller.
+func (r *BaseReconciler) SetWebhooksEnabled(enabled bool) {
+	r.Webhooks = enabled
 }
 
-func (r *BaseReconciler) ProcessDependencies(owner metav1.OwnerReference, ns string, template v1alpha1.Template, singleton bool, dep v1alpha1.AppDepsSpec) error {
-	instanceLabels := utils.AppInstanceLabels(owner.Name, template.Name, template.Version)
+// Eventf records an event on the instance, nothing is recorded before the recorder is set.
+func (r *BaseReconciler) Eventf(instance runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(instance, eventtype, reason, messageFmt, args...)
+}
+
+// EventResolveFailed records a warning event on the instance for the error returned when resolving the template.
+func (r *BaseReconciler) EventResolveFailed(instance runtime.Object, name string, err error) {
+	reason := utils.EventReasonInvalidTemplate
+	if errors.Is(err, template.ErrTemplateNotFound) {
+		reason = utils.EventReasonTemplateNotFound
+	} else if errors.Is(err, template.ErrInvalidValues) {
+		reason = utils.EventReasonInvalidValues
+	}
+	r.Eventf(instance, corev1.EventTypeWarning, reason, "%s: %v", name, err)
+}
+
+func (r *BaseReconciler) ProcessDependencies(instance client.Object, owner metav1.OwnerReference, app string, template v1alpha1.TemplateReference, singleton bool, dep v1alpha1.AppDepsSpec) error {
+	instanceLabels := utils.AppInstanceLabels(app, template.Name, template.Version)
 	ctx := context.Background()
 
+	inventory := util_client.NewInventory(r.Client)
 	for name, capsuleSpec := range dep.Capsules {
 		if !singleton {
 			name = fmt.Sprintf("%s-%s", owner.Name, name)
@@ -34,7 +73,7 @@ func (r *BaseReconciler) ProcessDependencies(owner metav1.OwnerReference, ns str
 		capsule := v1alpha1.Capsule{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      name,
-				Namespace: ns,
+				Namespace: instance.GetNamespace(),
 				OwnerReferences: []metav1.OwnerReference{
 					owner,
 				},
@@ -43,12 +82,32 @@ func (r *BaseReconciler) ProcessDependencies(owner metav1.OwnerReference, ns str
 			Spec: capsuleSpec,
 		}
 		level.Info(r.Logger).Log("msg", "start to create dependency", "instance", owner.Name, "type", "capsule", "name", name)
-		if err := util_client.CreateOrUpdateCapsule(ctx, r.Client, &capsule); err != nil {
+		if _, err := util_client.Apply(ctx, r.Client, &capsule); err != nil {
 			level.Warn(r.Logger).Log("msg", "failed to create dependency", "instance", owner.Name, "type", "capsule", "name", name, "err", err)
 			return err
 		}
+		if err := inventory.Add(&capsule); err != nil {
+			return err
+		}
 		level.Info(r.Logger).Log("msg", "create dependency success", "instance", owner.Name, "type", "capsule", "name", name)
 	}
 
-	return nil
+	return r.pruneDependencies(ctx, instance, owner, utils.InstanceSelector(app), inventory)
+}
+
+// PruneDependencies deletes the dependencies owned by the instance which match the selector.
+func (r *BaseReconciler) PruneDependencies(instance client.Object, owner metav1.OwnerReference, selector labels.Selector) error {
+	return r.pruneDependencies(context.Background(), instance, owner, selector, nil)
+}
+
+func (r *BaseReconciler) pruneDependencies(ctx context.Context, instance client.Object, owner metav1.OwnerReference, selector labels.Selector, inventory *util_client.Inventory) error {
+	pruned, err := util_client.PruneOwned(ctx, r.Client, &v1alpha1.CapsuleList{}, instance.GetNamespace(), selector, owner.UID, inventory)
+	for _, obj := range pruned {
+		level.Info(r.Logger).Log("msg", "pruned orphaned dependency", "instance", owner.Name, "type", "capsule", "name", obj.GetName())
+		r.Eventf(instance, corev1.EventTypeNormal, utils.EventReasonPruned, "Pruned Capsule %s", obj.GetName())
+	}
+	if err != nil {
+		level.Warn(r.Logger).Log("msg", "failed to prune dependencies", "instance", owner.Name, "err", err)
+	}
+	return err
 }
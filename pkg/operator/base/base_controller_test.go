@@ -0,0 +1,60 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+	"github.com/udmire/observability-operator/pkg/utils"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestBaseReconciler_EventResolveFailed(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantReason string
+	}{
+		{
+			name:       "template_not_found",
+			err:        fmt.Errorf("%w: node-exporter:latest", template.ErrTemplateNotFound),
+			wantReason: utils.EventReasonTemplateNotFound,
+		},
+		{
+			name:       "invalid_template",
+			err:        fmt.Errorf("%w: cannot decode deployment.yaml", template.ErrInvalidTemplate),
+			wantReason: utils.EventReasonInvalidTemplate,
+		},
+		{
+			name:       "invalid_values",
+			err:        fmt.Errorf("%w: values.port: Invalid value", template.ErrInvalidValues),
+			wantReason: utils.EventReasonInvalidValues,
+		},
+		{
+			name:       "unknown",
+			err:        errors.New("unknown"),
+			wantReason: utils.EventReasonInvalidTemplate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			r := &BaseReconciler{}
+			r.SetEventRecorder(recorder)
+			r.EventResolveFailed(&v1alpha1.Apps{}, "node", tt.err)
+
+			event := <-recorder.Events
+			if !strings.HasPrefix(event, "Warning "+tt.wantReason+" node: ") {
+				t.Errorf("EventResolveFailed() event = %v, want reason %v", event, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestBaseReconciler_Eventf_withoutRecorder(t *testing.T) {
+	r := &BaseReconciler{}
+	r.Eventf(&v1alpha1.Apps{}, "Normal", utils.EventReasonCleanedUp, "Cleaned up")
+}
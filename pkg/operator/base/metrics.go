@@ -0,0 +1,86 @@
+package base
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/udmire/observability-operator/api/v1alpha1"
+)
+
+// Metrics holds the reconcile metrics of a controller. The registerer is expected to be
+// wrapped with the kind of the instances reconciled by the controller.
+type Metrics struct {
+	ReconcileDuration *prometheus.HistogramVec
+	ReconcileErrors   *prometheus.CounterVec
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	return &Metrics{
+		ReconcileDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "observperator",
+			Name:      "reconcile_duration_seconds",
+			Help:      "Time spent reconciling an instance, by the template reconciled. The templates of the instances with several apployments are timed apart.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"namespace", "name", "template"}),
+		ReconcileErrors: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "observperator",
+			Name:      "reconcile_errors_total",
+			Help:      "Total number of failures reconciling an instance, by the template which failed.",
+		}, []string{"namespace", "name", "template"}),
+	}
+}
+
+// ObserveReconcile records the time spent reconciling the template of the instance since start.
+func (m *Metrics) ObserveReconcile(namespace, name, template string, start time.Time) {
+	m.ReconcileDuration.WithLabelValues(namespace, name, template).Observe(time.Since(start).Seconds())
+}
+
+// ReconcileFailed counts a failure reconciling the template of the instance.
+func (m *Metrics) ReconcileFailed(namespace, name, template string) {
+	m.ReconcileErrors.WithLabelValues(namespace, name, template).Inc()
+}
+
+// InstanceCounter counts the managed instances by template and version.
+type InstanceCounter func(ctx context.Context) (map[v1alpha1.TemplateReference]int, error)
+
+// instancesCollector reports the number of managed instances by template and version
+// when scraped, so instances deleted or switched to other templates are not reported.
+type instancesCollector struct {
+	logger log.Logger
+	count  InstanceCounter
+	desc   *prometheus.Desc
+}
+
+// NewInstancesCollector creates the collector reporting the instances counted by the counter.
+func NewInstancesCollector(count InstanceCounter, logger log.Logger) prometheus.Collector {
+	return &instancesCollector{
+		logger: logger,
+		count:  count,
+		desc: prometheus.NewDesc(
+			"observperator_managed_instances",
+			"Number of instances managed by the operator, by template and version.",
+			[]string{"template", "version"}, nil),
+	}
+}
+
+func (c *instancesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *instancesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+	if err != nil {
+		level.Debug(c.logger).Log("msg", "failed to count managed instances", "err", err)
+		return
+	}
+	for template, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), template.Name, template.Version)
+	}
+}
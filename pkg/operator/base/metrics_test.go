@@ -0,0 +1,51 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/udmire/observability-operator/api/v1alpha1"
+)
+
+func TestInstancesCollector(t *testing.T) {
+	tests := []struct {
+		name  string
+		count InstanceCounter
+		want  string
+	}{
+		{
+			name: "counted",
+			count: func(ctx context.Context) (map[v1alpha1.TemplateReference]int, error) {
+				return map[v1alpha1.TemplateReference]int{
+					{Name: "node-exporter", Version: "1.5.0"}:      2,
+					{Name: "kube-state-metrics", Version: "2.9.2"}: 1,
+				}, nil
+			},
+			want: `
+# HELP observperator_managed_instances Number of instances managed by the operator, by template and version.
+# TYPE observperator_managed_instances gauge
+observperator_managed_instances{template="kube-state-metrics",version="2.9.2"} 1
+observperator_managed_instances{template="node-exporter",version="1.5.0"} 2
+`,
+		},
+		{
+			name: "cache_not_started",
+			count: func(ctx context.Context) (map[v1alpha1.TemplateReference]int, error) {
+				return nil, errors.New("the cache is not started")
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewInstancesCollector(tt.count, log.NewNopLogger())
+			if err := testutil.CollectAndCompare(collector, strings.NewReader(tt.want)); err != nil {
+				t.Errorf("instancesCollector.Collect() %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log/level"
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MarkCondition records the result of a reconciling step in the status. The condition
+// is True when err is nil, otherwise it is False with the error as message.
+func MarkCondition(status *v1alpha1.CommonStatus, generation int64, conditionType, failedReason string, err error) {
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             v1alpha1.ReasonSucceeded,
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = failedReason
+		condition.Message = err.Error()
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// MarkBlocked marks the conditions of the steps not run because the step of the blocking condition
+// failed, so that they don't keep the result of an earlier reconcile.
+func MarkBlocked(status *v1alpha1.CommonStatus, generation int64, blockedBy string, conditionTypes ...string) {
+	for _, typ := range conditionTypes {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               typ,
+			Status:             metav1.ConditionUnknown,
+			ObservedGeneration: generation,
+			Reason:             v1alpha1.ReasonBlocked,
+			Message:            fmt.Sprintf("blocked by the failed %s step", blockedBy),
+		})
+	}
+}
+
+// Step is a reconciling step reported as a condition, with the reason of its failure.
+type Step struct {
+	ConditionType string
+	FailedReason  string
+}
+
+// InstanceSteps are the successive steps of reconciling an instance.
+var InstanceSteps = []Step{
+	{ConditionType: v1alpha1.ConditionTemplateResolved, FailedReason: v1alpha1.ReasonResolveFailed},
+	{ConditionType: v1alpha1.ConditionDependenciesReady, FailedReason: v1alpha1.ReasonDependencyFailed},
+	{ConditionType: v1alpha1.ConditionApplied, FailedReason: v1alpha1.ReasonApplyFailed},
+}
+
+// MarkSteps records the errors collected for the successive steps. A step without errors after a
+// failed step is marked blocked, as it was not run for the workers failed earlier.
+func MarkSteps(status *v1alpha1.CommonStatus, generation int64, errs *ConditionErrors, steps ...Step) {
+	blockedBy := ""
+	for _, step := range steps {
+		err := errs.Err(step.ConditionType)
+		if err == nil && len(blockedBy) > 0 {
+			MarkBlocked(status, generation, blockedBy, step.ConditionType)
+			continue
+		}
+		MarkCondition(status, generation, step.ConditionType, step.FailedReason, err)
+		if err != nil && len(blockedBy) == 0 {
+			blockedBy = step.ConditionType
+		}
+	}
+}
+
+// MarkReady aggregates the given condition types into the Ready condition, and updates
+// the observed generation and last error of the status.
+func MarkReady(status *v1alpha1.CommonStatus, generation int64, conditionTypes ...string) {
+	ready := metav1.Condition{
+		Type:               v1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             v1alpha1.ReasonSucceeded,
+	}
+	for _, typ := range conditionTypes {
+		condition := meta.FindStatusCondition(status.Conditions, typ)
+		if condition != nil && condition.Status != metav1.ConditionTrue {
+			ready.Status = metav1.ConditionFalse
+			ready.Reason = condition.Reason
+			ready.Message = condition.Message
+			break
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, ready)
+
+	status.ObservedGeneration = generation
+	status.LastError = ready.Message
+}
+
+// UpdateStatus writes the status of the instance through the status client.
+func (r *BaseReconciler) UpdateStatus(ctx context.Context, instance client.Object) error {
+	if err := r.Status().Update(ctx, instance); err != nil {
+		level.Warn(r.Logger).Log("msg", "failed to update status", "instance", instance.GetName(), "err", err)
+		return err
+	}
+	return nil
+}
+
+// ConditionErrors collects the failures of concurrent workers by condition type.
+type ConditionErrors struct {
+	mutex  sync.Mutex
+	errors map[string]map[string]error
+}
+
+func (c *ConditionErrors) Add(conditionType, name string, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.errors == nil {
+		c.errors = map[string]map[string]error{}
+	}
+	if _, exists := c.errors[conditionType]; !exists {
+		c.errors[conditionType] = map[string]error{}
+	}
+	c.errors[conditionType][name] = err
+}
+
+// Err returns the failures of the condition type combined into one error, nil if none.
+func (c *ConditionErrors) Err(conditionType string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errs := c.errors[conditionType]
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var messages []string
+	for name, err := range errs {
+		messages = append(messages, fmt.Sprintf("%s: %s", name, err.Error()))
+	}
+	sort.Strings(messages)
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// All returns the failures of all the condition types combined into one error, nil if none.
+func (c *ConditionErrors) All() error {
+	c.mutex.Lock()
+	conditionTypes := make([]string, 0, len(c.errors))
+	for conditionType := range c.errors {
+		conditionTypes = append(conditionTypes, conditionType)
+	}
+	c.mutex.Unlock()
+	sort.Strings(conditionTypes)
+
+	var errs []error
+	for _, conditionType := range conditionTypes {
+		if err := c.Err(conditionType); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,120 @@
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMarkReady(t *testing.T) {
+	type args struct {
+		resolved error
+		applied  error
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       metav1.ConditionStatus
+		wantReason string
+		wantError  string
+	}{
+		{
+			name: "all_succeeded",
+			args: args{},
+			want: metav1.ConditionTrue, wantReason: v1alpha1.ReasonSucceeded,
+		},
+		{
+			name: "template_not_found",
+			args: args{resolved: errors.New("template app:latest not found")},
+			want: metav1.ConditionFalse, wantReason: v1alpha1.ReasonResolveFailed, wantError: "template app:latest not found",
+		},
+		{
+			name: "apply_failed",
+			args: args{applied: errors.New("forbidden")},
+			want: metav1.ConditionFalse, wantReason: v1alpha1.ReasonApplyFailed, wantError: "forbidden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &v1alpha1.CommonStatus{LastError: "stale"}
+			MarkCondition(status, 2, v1alpha1.ConditionTemplateResolved, v1alpha1.ReasonResolveFailed, tt.args.resolved)
+			MarkCondition(status, 2, v1alpha1.ConditionApplied, v1alpha1.ReasonApplyFailed, tt.args.applied)
+			MarkReady(status, 2, v1alpha1.ConditionTemplateResolved, v1alpha1.ConditionApplied)
+
+			ready := meta.FindStatusCondition(status.Conditions, v1alpha1.ConditionReady)
+			if ready == nil {
+				t.Fatalf("MarkReady() did not set the %s condition", v1alpha1.ConditionReady)
+			}
+			if ready.Status != tt.want || ready.Reason != tt.wantReason {
+				t.Errorf("MarkReady() = %v/%v, want %v/%v", ready.Status, ready.Reason, tt.want, tt.wantReason)
+			}
+			if status.LastError != tt.wantError {
+				t.Errorf("MarkReady() lastError = %v, want %v", status.LastError, tt.wantError)
+			}
+			if status.ObservedGeneration != 2 {
+				t.Errorf("MarkReady() observedGeneration = %v, want %v", status.ObservedGeneration, 2)
+			}
+		})
+	}
+}
+
+func TestMarkSteps(t *testing.T) {
+	status := &v1alpha1.CommonStatus{}
+	var succeeded ConditionErrors
+	MarkSteps(status, 1, &succeeded, InstanceSteps...)
+
+	var errs ConditionErrors
+	errs.Add(v1alpha1.ConditionTemplateResolved, "node-exporter", errors.New("template not found"))
+	MarkSteps(status, 2, &errs, InstanceSteps...)
+	MarkReady(status, 2, v1alpha1.ConditionTemplateResolved, v1alpha1.ConditionDependenciesReady, v1alpha1.ConditionApplied)
+
+	want := map[string]metav1.ConditionStatus{
+		v1alpha1.ConditionTemplateResolved:  metav1.ConditionFalse,
+		v1alpha1.ConditionDependenciesReady: metav1.ConditionUnknown,
+		v1alpha1.ConditionApplied:           metav1.ConditionUnknown,
+		v1alpha1.ConditionReady:             metav1.ConditionFalse,
+	}
+	for typ, wantStatus := range want {
+		condition := meta.FindStatusCondition(status.Conditions, typ)
+		if condition == nil || condition.Status != wantStatus || condition.ObservedGeneration != 2 {
+			t.Errorf("MarkSteps() %s = %+v, want %v at generation 2", typ, condition, wantStatus)
+		}
+	}
+	if applied := meta.FindStatusCondition(status.Conditions, v1alpha1.ConditionApplied); applied.Reason != v1alpha1.ReasonBlocked {
+		t.Errorf("MarkSteps() %s reason = %v, want %v", v1alpha1.ConditionApplied, applied.Reason, v1alpha1.ReasonBlocked)
+	}
+	if ready := meta.FindStatusCondition(status.Conditions, v1alpha1.ConditionReady); ready.Reason != v1alpha1.ReasonResolveFailed {
+		t.Errorf("MarkReady() reason = %v, want %v", ready.Reason, v1alpha1.ReasonResolveFailed)
+	}
+}
+
+func TestConditionErrors_Err(t *testing.T) {
+	var errs ConditionErrors
+	if err := errs.Err(v1alpha1.ConditionApplied); err != nil {
+		t.Errorf("ConditionErrors.Err() = %v, want nil", err)
+	}
+
+	errs.Add(v1alpha1.ConditionApplied, "node-exporter", errors.New("forbidden"))
+	errs.Add(v1alpha1.ConditionApplied, "kube-state-metrics", errors.New("timeout"))
+	want := "kube-state-metrics: timeout; node-exporter: forbidden"
+	if err := errs.Err(v1alpha1.ConditionApplied); err == nil || err.Error() != want {
+		t.Errorf("ConditionErrors.Err() = %v, want %v", err, want)
+	}
+}
+
+func TestConditionErrors_All(t *testing.T) {
+	var errs ConditionErrors
+	if err := errs.All(); err != nil {
+		t.Errorf("ConditionErrors.All() = %v, want nil", err)
+	}
+
+	errs.Add(v1alpha1.ConditionTemplateResolved, "node-exporter", errors.New("template not found"))
+	errs.Add(v1alpha1.ConditionApplied, "kube-state-metrics", errors.New("timeout"))
+	want := "[kube-state-metrics: timeout, node-exporter: template not found]"
+	if err := errs.All(); err == nil || err.Error() != want {
+		t.Errorf("ConditionErrors.All() = %v, want %v", err, want)
+	}
+}
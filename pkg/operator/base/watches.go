@@ -0,0 +1,152 @@
+package base
+
+import (
+	"context"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/utils"
+)
+
+// InstanceMatcher tells whether the instance renders the objects labeled with the named
+// instance, the name is the apployment name for Apps and Exporters.
+type InstanceMatcher func(instance client.Object, name string) bool
+
+// MatchName matches the instances rendering the objects under their own name.
+func MatchName(instance client.Object, name string) bool {
+	return instance.GetName() == name
+}
+
+// WatchChildren watches the kinds of the objects generated by the controller, so that manual
+// changes or deletions of them are reverted. The events are mapped back to the owning instances.
+func WatchChildren(blder *builder.Builder, c client.Client, kinds []client.Object, newList func() client.ObjectList, match InstanceMatcher) *builder.Builder {
+	eventHandler := handler.EnqueueRequestsFromMapFunc(OwnerRequests(c, newList, match))
+	for _, kind := range kinds {
+		blder = blder.Watches(kind, eventHandler, builder.WithPredicates(ChildPredicate()))
+	}
+	return blder
+}
+
+// ChildPredicate filters the events of the objects managed by the operator. Updates are only
+// passed when the spec, the labels or the annotations change, status updates are dropped.
+func ChildPredicate() predicate.Predicate {
+	managed := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		labels := obj.GetLabels()
+		return labels[utils.ManagedByLabel] == utils.DefaultManagedByValue && len(labels[utils.InstanceLabel]) > 0
+	})
+	changed := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			// Kinds without status (configmaps, secrets, rbac) do not track generations.
+			if e.ObjectNew.GetGeneration() == 0 || e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() {
+				return true
+			}
+			return !equalMaps(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+				!equalMaps(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations())
+		},
+	}
+	return predicate.And(managed, changed)
+}
+
+// OwnerRequests maps the child to the instances owning it. The instance label of the child names
+// the instance, the owner references tell the owner apart from instances with the same name in other
+// namespaces, which is needed for the cluster scoped children.
+func OwnerRequests(c client.Client, newList func() client.ObjectList, match InstanceMatcher) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		name := obj.GetLabels()[utils.InstanceLabel]
+		owners := make(map[types.UID]struct{}, len(obj.GetOwnerReferences()))
+		for _, ref := range obj.GetOwnerReferences() {
+			owners[ref.UID] = struct{}{}
+		}
+		if len(name) == 0 || len(owners) == 0 {
+			return nil
+		}
+
+		var opts []client.ListOption
+		if len(obj.GetNamespace()) > 0 {
+			opts = append(opts, client.InNamespace(obj.GetNamespace()))
+		}
+		instances, err := listObjects(ctx, c, newList(), opts...)
+		if err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, instance := range instances {
+			if _, owned := owners[instance.GetUID()]; owned && match(instance, name) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(instance)})
+			}
+		}
+		return requests
+	}
+}
+
+// TemplateMatcher tells whether the spec of the instance references the named template.
+type TemplateMatcher func(instance client.Object, template string) bool
+
+// WatchTemplates enqueues the instances referencing a template when a version of the template
+// appears in or disappears from the provider, so that the instances tracking the latest version
+// pick up the change.
+func WatchTemplates(blder *builder.Builder, c client.Client, tp provider.TemplateProvider, newList func() client.ObjectList, match TemplateMatcher, logger log.Logger) *builder.Builder {
+	events := make(chan event.GenericEvent)
+	tp.Subscribe(func(name string) {
+		// The listener is called by the provider, the events are sent in the background
+		// so that the provider is not blocked until the controller takes them.
+		go func() {
+			instances, err := listObjects(context.Background(), c, newList())
+			if err != nil {
+				level.Warn(logger).Log("msg", "failed to list instances for template change", "template", name, "err", err)
+				return
+			}
+			for _, instance := range instances {
+				if match(instance, name) {
+					events <- event.GenericEvent{Object: instance}
+				}
+			}
+		}()
+	})
+	return blder.WatchesRawSource(&source.Channel{Source: events}, &handler.EnqueueRequestForObject{})
+}
+
+func listObjects(ctx context.Context, c client.Client, list client.ObjectList, opts ...client.ListOption) ([]client.Object, error) {
+	if err := c.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]client.Object, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(client.Object); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+func equalMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, exists := b[key]; !exists || other != value {
+			return false
+		}
+	}
+	return true
+}
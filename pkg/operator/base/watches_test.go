@@ -0,0 +1,70 @@
+package base
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	rbac_v1 "k8s.io/api/rbac/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/utils"
+)
+
+func TestOwnerRequests(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	agents := func(namespace string, uid types.UID) *v1alpha1.Agents {
+		return &v1alpha1.Agents{ObjectMeta: meta_v1.ObjectMeta{Name: "agents", Namespace: namespace, UID: uid}}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(agents("monitoring", "uid-1"), agents("default", "uid-2")).Build()
+	newList := func() client.ObjectList { return &v1alpha1.AgentsList{} }
+
+	child := func(instance string, owners ...types.UID) *rbac_v1.ClusterRole {
+		role := &rbac_v1.ClusterRole{ObjectMeta: meta_v1.ObjectMeta{
+			Name:   "agents-node-exporter",
+			Labels: utils.AppInstanceLabels(instance, "node-exporter", "1.5.0"),
+		}}
+		for _, owner := range owners {
+			role.OwnerReferences = append(role.OwnerReferences, meta_v1.OwnerReference{Kind: "Agents", Name: "agents", UID: owner})
+		}
+		return role
+	}
+
+	tests := []struct {
+		name  string
+		child client.Object
+		want  []reconcile.Request
+	}{
+		{
+			name:  "cluster scoped child",
+			child: child("agents", "uid-2"),
+			want:  []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "agents"}}},
+		},
+		{
+			name:  "other instance",
+			child: child("exporters", "uid-2"),
+		},
+		{
+			name:  "not owned",
+			child: child("agents", "uid-3"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OwnerRequests(c, newList, MatchName)(context.Background(), tt.child)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OwnerRequests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+)
+
+// ValidateFunc returns the field errors of the instance.
+type ValidateFunc func(instance client.Object) field.ErrorList
+
+// NewValidator returns the admission validator of the instances of the kind, the instances
+// with field errors are rejected on creation and update.
+func NewValidator(kind string, validate ValidateFunc) admission.CustomValidator {
+	return &validator{kind: kind, validate: validate}
+}
+
+type validator struct {
+	kind     string
+	validate ValidateFunc
+}
+
+func (v *validator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validateObject(obj)
+}
+
+func (v *validator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validateObject(newObj)
+}
+
+func (v *validator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *validator) validateObject(obj runtime.Object) error {
+	instance, ok := obj.(client.Object)
+	if !ok {
+		return fmt.Errorf("expected a %s but got a %T", v.kind, obj)
+	}
+
+	errs := v.validate(instance)
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind(v.kind).GroupKind(), instance.GetName(), errs)
+}
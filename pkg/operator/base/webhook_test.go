@@ -0,0 +1,41 @@
+package base
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+)
+
+func TestValidator(t *testing.T) {
+	validator := NewValidator("Agents", func(instance client.Object) field.ErrorList {
+		if instance.GetName() == "invalid" {
+			return field.ErrorList{field.Required(field.NewPath("spec", "values", "token"), "required by the template")}
+		}
+		return nil
+	})
+
+	valid := &v1alpha1.Agents{}
+	valid.Name = "valid"
+	if _, err := validator.ValidateCreate(context.Background(), valid); err != nil {
+		t.Errorf("ValidateCreate() error = %v", err)
+	}
+
+	invalid := &v1alpha1.Agents{}
+	invalid.Name = "invalid"
+	_, err := validator.ValidateUpdate(context.Background(), valid, invalid)
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("ValidateUpdate() error = %v, want invalid", err)
+	}
+	if want := `Agents.udmire.cn "invalid" is invalid: spec.values.token: Required value: required by the template`; err.Error() != want {
+		t.Errorf("ValidateUpdate() error = %v, want %v", err, want)
+	}
+
+	if _, err = validator.ValidateDelete(context.Background(), invalid); err != nil {
+		t.Errorf("ValidateDelete() error = %v", err)
+	}
+}
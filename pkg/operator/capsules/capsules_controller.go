@@ -2,13 +2,17 @@ package capsules
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -16,37 +20,49 @@ import (
 	"github.com/udmire/observability-operator/api/v1alpha1"
 	"github.com/udmire/observability-operator/pkg/capsules/reconcile"
 	"github.com/udmire/observability-operator/pkg/capsules/specs"
+	"github.com/udmire/observability-operator/pkg/operator/base"
 	info "github.com/udmire/observability-operator/pkg/operator/providers"
 	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/utils"
 )
 
 // CapsulesReconciler reconciles a Capsule object
 type CapsulesReconciler struct {
-	*services.BasicService
-
-	client.Client
-	Scheme *runtime.Scheme
+	base.BaseReconciler
 
 	mgr ctrl.Manager
 	cnp info.StringProvider
+	tp  provider.TemplateProvider
 
 	handler       specs.CapsuleHandler
 	capReconciler reconcile.CapsuleReconciler
-	logger        log.Logger
 }
 
-func New(client client.Client, schema *runtime.Scheme, tp provider.TemplateProvider, logger log.Logger) *CapsulesReconciler {
+func New(client client.Client, schema *runtime.Scheme, tp provider.TemplateProvider, reg prometheus.Registerer, logger log.Logger) *CapsulesReconciler {
+	reg = prometheus.WrapRegistererWith(prometheus.Labels{"kind": "Capsule"}, reg)
 	reconciler := &CapsulesReconciler{
-		Client: client,
-		Scheme: schema,
+		BaseReconciler: base.BaseReconciler{
+			Client: client,
+			Scheme: schema,
+			Logger: logger,
+
+			Metrics: base.NewMetrics(reg),
+		},
 
-		handler:       specs.New(tp, logger),
-		capReconciler: reconcile.New(logger, client),
-		logger:        logger,
+		tp:            tp,
+		handler:       specs.New(tp, reg, logger),
+		capReconciler: reconcile.New(logger, client, reg),
 	}
 	reconciler.BasicService = services.NewIdleService(func(serviceContext context.Context) error {
-		return reconciler.SetupWithManager(reconciler.mgr)
+		if err := reconciler.SetupWithManager(reconciler.mgr); err != nil {
+			return err
+		}
+		if !reconciler.Webhooks {
+			return nil
+		}
+		return reconciler.SetupWebhookWithManager(reconciler.mgr)
 	}, nil)
+	reg.MustRegister(base.NewInstancesCollector(reconciler.countInstances, logger))
 	return reconciler
 }
 
@@ -56,11 +72,17 @@ func (r *CapsulesReconciler) SetManager(mgr ctrl.Manager) {
 
 func (r *CapsulesReconciler) SetClusterNameProvider(cnp info.StringProvider) {
 	r.cnp = cnp
+	r.handler.SetClusterNameProvider(cnp)
 }
 
-//+kubebuilder:rbac:groups=udmire.cn,resources=capsule,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=udmire.cn,resources=capsule/status,verbs=get;update;patch
-//+kubebuilder:rbac:groups=udmire.cn,resources=capsule/finalizers,verbs=update
+func (r *CapsulesReconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.BaseReconciler.SetEventRecorder(recorder)
+	r.capReconciler.SetEventRecorder(recorder)
+}
+
+//+kubebuilder:rbac:groups=udmire.cn,resources=capsules,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=udmire.cn,resources=capsules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=udmire.cn,resources=capsules/finalizers,verbs=update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,18 +94,21 @@ func (r *CapsulesReconciler) SetClusterNameProvider(cnp info.StringProvider) {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.15.0/pkg/reconcile
 func (r *CapsulesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	level.Info(r.logger).Log("msg", "reconciling capsule")
-	defer level.Info(r.logger).Log("msg", "done reconciling capsule")
+	level.Info(r.Logger).Log("msg", "reconciling capsule")
+	defer level.Info(r.Logger).Log("msg", "done reconciling capsule")
+	start := time.Now()
 
 	instance := v1alpha1.Capsule{}
 	if err := r.Get(ctx, req.NamespacedName, &instance); apierrors.IsNotFound(err) {
-		level.Error(r.logger).Log("msg", "detected deleted capsule", "err", err)
+		level.Error(r.Logger).Log("msg", "detected deleted capsule", "err", err)
 		return ctrl.Result{}, nil
 	} else if err != nil {
-		level.Error(r.logger).Log("msg", "unable to get capsule", "err", err)
+		level.Error(r.Logger).Log("msg", "unable to get capsule", "err", err)
 		return ctrl.Result{}, nil
 	}
 
+	defer r.Metrics.ObserveReconcile(req.Namespace, req.Name, instance.Spec.Template.Name, start)
+
 	r.normalize(&instance)
 	owner := metav1.OwnerReference{
 		APIVersion:         instance.APIVersion,
@@ -94,23 +119,54 @@ func (r *CapsulesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		UID:                instance.UID,
 	}
 
+	err := r.reconcileCapsule(ctx, owner, &instance)
+	if err != nil {
+		r.Metrics.ReconcileFailed(instance.Namespace, instance.Name, instance.Spec.Template.Name)
+	}
+	base.MarkReady(&instance.Status.CommonStatus, instance.Generation,
+		v1alpha1.ConditionTemplateResolved, v1alpha1.ConditionApplied)
+	if statusErr := r.UpdateStatus(ctx, &instance); err == nil {
+		err = statusErr
+	}
+
+	return ctrl.Result{}, err
+}
+
+func (r *CapsulesReconciler) reconcileCapsule(ctx context.Context, owner metav1.OwnerReference, instance *v1alpha1.Capsule) error {
+	status := &instance.Status.CommonStatus
+
 	manifest, err := r.handler.Handle(instance.Spec)
+	base.MarkCondition(status, instance.Generation, v1alpha1.ConditionTemplateResolved, v1alpha1.ReasonResolveFailed, err)
 	if err != nil {
-		level.Error(r.logger).Log("msg", "failed to generate manifests", "instance", instance.Name, "capsule", instance.Spec.Name, "err", err)
+		level.Error(r.Logger).Log("msg", "failed to generate manifests", "instance", instance.Name, "capsule", instance.Spec.Name, "err", err)
+		r.EventResolveFailed(instance, instance.Name, err)
+		base.MarkBlocked(status, instance.Generation, v1alpha1.ConditionTemplateResolved, v1alpha1.ConditionApplied)
+		return err
 	}
+	instance.Status.Template = v1alpha1.TemplateReference{Name: manifest.TemplateName, Version: manifest.TemplateVersion}
+	instance.Status.Revision = manifest.TemplateRevision
 
-	err = r.capReconciler.Reconcile(ctx, owner, manifest)
+	err = r.capReconciler.Reconcile(ctx, instance, owner, manifest)
+	base.MarkCondition(status, instance.Generation, v1alpha1.ConditionApplied, v1alpha1.ReasonApplyFailed, err)
 	if err != nil {
-		level.Error(r.logger).Log("msg", "failed to apply manifests", "instance", instance.Name, "capsule", instance.Spec.Name, "err", err)
+		level.Error(r.Logger).Log("msg", "failed to apply manifests", "instance", instance.Name, "capsule", instance.Spec.Name, "err", err)
+		r.Eventf(instance, corev1.EventTypeWarning, utils.EventReasonApplyFailed, "%s: %v", instance.Name, err)
+		return err
 	}
 
-	return ctrl.Result{}, nil
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *CapsulesReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.Capsule{}).
+	blder := ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Capsule{})
+	references := func(instance client.Object, name string) bool {
+		return instance.(*v1alpha1.Capsule).Spec.Template.Name == name
+	}
+	newList := func() client.ObjectList { return &v1alpha1.CapsuleList{} }
+	blder = base.WatchChildren(blder, r.Client, reconcile.Kinds(), newList, base.MatchName)
+	return base.WatchTemplates(blder, r.Client, r.tp, newList, references, r.Logger).
 		Complete(r)
 }
 
@@ -118,3 +174,19 @@ func (r *CapsulesReconciler) normalize(instance *v1alpha1.Capsule) {
 	instance.Spec.Namespace = instance.Namespace
 	instance.Spec.Name = instance.Name
 }
+
+// countInstances counts the capsules by the templates resolved for them.
+func (r *CapsulesReconciler) countInstances(ctx context.Context) (map[v1alpha1.TemplateReference]int, error) {
+	list := &v1alpha1.CapsuleList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[v1alpha1.TemplateReference]int)
+	for _, instance := range list.Items {
+		if len(instance.Status.Template.Name) > 0 {
+			counts[instance.Status.Template]++
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,24 @@
+package capsules
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/operator/base"
+)
+
+//+kubebuilder:webhook:path=/validate-udmire-cn-v1alpha1-capsule,mutating=false,failurePolicy=fail,sideEffects=None,groups=udmire.cn,resources=capsules,verbs=create;update,versions=v1alpha1,name=vcapsule.udmire.cn,admissionReviewVersions=v1
+
+// SetupWebhookWithManager sets up the webhook validating the values of the capsules.
+func (r *CapsulesReconciler) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Capsule{}).
+		WithValidator(base.NewValidator("Capsule", r.validate)).
+		Complete()
+}
+
+func (r *CapsulesReconciler) validate(instance client.Object) field.ErrorList {
+	return r.handler.Validate(instance.(*v1alpha1.Capsule).Spec, field.NewPath("spec"))
+}
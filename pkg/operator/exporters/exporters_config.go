@@ -1,11 +1,16 @@
 package exporters
 
-import "flag"
+import (
+	"flag"
+	"time"
+)
 
 type Config struct {
-	Concurrency int
+	Concurrency    int           `yaml:"concurrency"`
+	ResyncInterval time.Duration `yaml:"resync_interval"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&c.Concurrency, "exporters.concurrency", 3, "Max concurrent deploying exporters.")
+	f.DurationVar(&c.ResyncInterval, "exporters.resync-interval", 10*time.Minute, "Interval to reconcile the exporters again even though nothing changed, 0 to disable.")
 }
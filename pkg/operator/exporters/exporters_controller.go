@@ -19,13 +19,17 @@ package exporters
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,6 +41,7 @@ import (
 	"github.com/udmire/observability-operator/pkg/operator/base"
 	info "github.com/udmire/observability-operator/pkg/operator/providers"
 	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/utils"
 )
 
 // ExportersReconciler reconciles a Exporters object
@@ -47,26 +52,38 @@ type ExportersReconciler struct {
 
 	mgr ctrl.Manager
 	cnp info.StringProvider
+	tp  provider.TemplateProvider
 
 	handler       specs.AppHandler
 	appReconciler reconcile.AppReconciler
 }
 
-func New(client client.Client, schema *runtime.Scheme, config Config, tp provider.TemplateProvider, logger log.Logger) *ExportersReconciler {
+func New(client client.Client, schema *runtime.Scheme, config Config, tp provider.TemplateProvider, reg prometheus.Registerer, logger log.Logger) *ExportersReconciler {
+	reg = prometheus.WrapRegistererWith(prometheus.Labels{"kind": "Exporters"}, reg)
 	reconciler := &ExportersReconciler{
 		BaseReconciler: base.BaseReconciler{
 			Client: client,
 			Scheme: schema,
 			Logger: logger,
+
+			Metrics: base.NewMetrics(reg),
 		},
 		cfg: config,
 
-		handler:       specs.New(tp, logger),
-		appReconciler: reconcile.New(logger, client),
+		tp:            tp,
+		handler:       specs.New(tp, reg, logger),
+		appReconciler: reconcile.New(logger, client, reg),
 	}
 	reconciler.BasicService = services.NewIdleService(func(serviceContext context.Context) error {
-		return reconciler.SetupWithManager(reconciler.mgr)
+		if err := reconciler.SetupWithManager(reconciler.mgr); err != nil {
+			return err
+		}
+		if !reconciler.Webhooks {
+			return nil
+		}
+		return reconciler.SetupWebhookWithManager(reconciler.mgr)
 	}, nil)
+	reg.MustRegister(base.NewInstancesCollector(reconciler.countInstances, logger))
 	return reconciler
 }
 
@@ -76,6 +93,12 @@ func (r *ExportersReconciler) SetManager(mgr ctrl.Manager) {
 
 func (r *ExportersReconciler) SetClusterNameProvider(cnp info.StringProvider) {
 	r.cnp = cnp
+	r.handler.SetClusterNameProvider(cnp)
+}
+
+func (r *ExportersReconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.BaseReconciler.SetEventRecorder(recorder)
+	r.appReconciler.SetEventRecorder(recorder)
 }
 
 //+kubebuilder:rbac:groups=udmire.cn,resources=exporters,verbs=get;list;watch;create;update;patch;delete
@@ -104,8 +127,6 @@ func (r *ExportersReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	r.normalizeExporters(instance)
-
 	finalizerName := "exporters.udmire.cn/finalizer"
 	if instance.ObjectMeta.DeletionTimestamp.IsZero() {
 		if !controllerutil.ContainsFinalizer(instance, finalizerName) {
@@ -117,12 +138,14 @@ func (r *ExportersReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	} else {
 		// The object is being deleted
 		if controllerutil.ContainsFinalizer(instance, finalizerName) {
+			r.normalizeExporters(instance)
 			for _, exploy := range instance.Spec.Exployments {
 				selector := r.handler.Selector(exploy)
 				if err := r.appReconciler.CleanClusterLayerResources(instance.UID, selector); err != nil {
 					return ctrl.Result{}, err
 				}
 			}
+			r.Eventf(instance, corev1.EventTypeNormal, utils.EventReasonCleanedUp, "Cleaned up cluster layer resources")
 
 			// remove our finalizer from the list and update it.
 			controllerutil.RemoveFinalizer(instance, finalizerName)
@@ -135,6 +158,9 @@ func (r *ExportersReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
+	// Normalize after the finalizer is persisted, the defaults must not be written back into the spec.
+	r.normalizeExporters(instance)
+
 	owner := metav1.OwnerReference{
 		APIVersion:         instance.APIVersion,
 		BlockOwnerDeletion: pointer.Bool(true),
@@ -144,7 +170,24 @@ func (r *ExportersReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		UID:                instance.UID,
 	}
 
+	var names []string
+	for name := range instance.Spec.Exployments {
+		names = append(names, name)
+	}
+	tracker := base.NewApploymentTracker(instance.Status.Exployments, names)
+	if instance.Status.ObservedGeneration != instance.Generation {
+		// The spec changed, publish the rollout before rendering.
+		for _, name := range names {
+			tracker.Transit(name, v1alpha1.PhaseRendering, nil)
+		}
+		instance.Status.Exployments = tracker.Commit()
+		if err := r.UpdateStatus(ctx, instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	var wg sync.WaitGroup
+	var errs base.ConditionErrors
 	semaphore := make(chan struct{}, r.cfg.Concurrency)
 	for _, exploy := range instance.Spec.Exployments {
 		wg.Add(1)
@@ -154,36 +197,90 @@ func (r *ExportersReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			defer func() {
 				<-semaphore
 			}()
+			defer r.Metrics.ObserveReconcile(instance.Namespace, instance.Name, app.Template.Name, time.Now())
 
+			tracker.Transit(app.Name, v1alpha1.PhaseRendering, nil)
 			manifest, err := r.handler.Handle(app)
 			if err != nil {
 				level.Error(r.Logger).Log("msg", "failed to generate manifests", "instance", instance.Name, "exporter", app.Name, "err", err)
+				r.EventResolveFailed(instance, app.Name, err)
+				r.Metrics.ReconcileFailed(instance.Namespace, instance.Name, app.Template.Name)
+				errs.Add(v1alpha1.ConditionTemplateResolved, app.Name, err)
+				tracker.Transit(app.Name, v1alpha1.PhaseFailed, err)
 				return
 			}
+			tracker.Resolve(app.Name, manifest.TemplateName, manifest.TemplateVersion, manifest.TemplateRevision)
 
-			if err := r.ProcessDependencies(owner, instance.Namespace, app.Template, app.Singleton, app.Dependencies); err != nil {
+			tracker.Transit(app.Name, v1alpha1.PhaseApplying, nil)
+			if err := r.ProcessDependencies(instance, owner, app.Name, app.Template, app.Singleton, app.Dependencies); err != nil {
 				level.Error(r.Logger).Log("msg", "failed to create dependencies", "instance", instance.Name, "exporter", app.Name, "err", err)
+				r.Eventf(instance, corev1.EventTypeWarning, utils.EventReasonDependencyFailed, "%s: %v", app.Name, err)
+				r.Metrics.ReconcileFailed(instance.Namespace, instance.Name, app.Template.Name)
+				errs.Add(v1alpha1.ConditionDependenciesReady, app.Name, err)
+				tracker.Transit(app.Name, v1alpha1.PhaseFailed, err)
 				return
 			}
 
-			err = r.appReconciler.Reconcile(owner, "exporter", app.Name, manifest)
+			err = r.appReconciler.Reconcile(instance, owner, "exporter", app.Name, manifest)
 			if err != nil {
 				level.Error(r.Logger).Log("msg", "failed to apply manifests", "instance", instance.Name, "exporter", app.Name, "err", err)
+				r.Eventf(instance, corev1.EventTypeWarning, utils.EventReasonApplyFailed, "%s: %v", app.Name, err)
+				r.Metrics.ReconcileFailed(instance.Namespace, instance.Name, app.Template.Name)
+				errs.Add(v1alpha1.ConditionApplied, app.Name, err)
+				tracker.Transit(app.Name, v1alpha1.PhaseFailed, err)
+				return
 			}
+			tracker.Transit(app.Name, v1alpha1.PhaseReady, nil)
 		}(exploy)
 	}
 	wg.Wait()
 
-	return ctrl.Result{}, nil
+	// Prune the resources left by the apployments removed from the spec.
+	orphaned := utils.OrphanedInstancesSelector(names)
+	if err := r.appReconciler.Prune(instance, owner, orphaned); err != nil {
+		level.Error(r.Logger).Log("msg", "failed to prune orphaned resources", "instance", instance.Name, "err", err)
+		errs.Add(v1alpha1.ConditionApplied, "prune", err)
+	}
+	if err := r.PruneDependencies(instance, owner, orphaned); err != nil {
+		errs.Add(v1alpha1.ConditionDependenciesReady, "prune", err)
+	}
+
+	instance.Status.Exployments = tracker.Commit()
+
+	status := &instance.Status.CommonStatus
+	base.MarkSteps(status, instance.Generation, &errs, base.InstanceSteps...)
+	base.MarkReady(status, instance.Generation,
+		v1alpha1.ConditionTemplateResolved, v1alpha1.ConditionDependenciesReady, v1alpha1.ConditionApplied)
+	if err := r.UpdateStatus(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Failed apployments are retried with the backoff of the controller.
+	return ctrl.Result{RequeueAfter: r.cfg.ResyncInterval}, errs.All()
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ExportersReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	blder := ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Exporters{}).
 		Owns(&v1alpha1.Exporters{}).
 		Owns(&v1alpha1.Apps{}).
-		Owns(&v1alpha1.Capsule{}).
+		Owns(&v1alpha1.Capsule{})
+	owns := func(instance client.Object, name string) bool {
+		_, exists := instance.(*v1alpha1.Exporters).Spec.Exployments[name]
+		return exists
+	}
+	references := func(instance client.Object, name string) bool {
+		for _, app := range instance.(*v1alpha1.Exporters).Spec.Exployments {
+			if app.Template.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	newList := func() client.ObjectList { return &v1alpha1.ExportersList{} }
+	blder = base.WatchChildren(blder, r.Client, reconcile.Kinds(), newList, owns)
+	return base.WatchTemplates(blder, r.Client, r.tp, newList, references, r.Logger).
 		Complete(r)
 }
 
@@ -194,5 +291,24 @@ func (r *ExportersReconciler) normalizeExporters(instance *v1alpha1.Exporters) {
 		if len(exporter.Registry) == 0 {
 			exporter.Registry = instance.Spec.Registry
 		}
+		instance.Spec.Exployments[name] = exporter
+	}
+}
+
+// countInstances counts the exporters by the templates resolved for them.
+func (r *ExportersReconciler) countInstances(ctx context.Context) (map[v1alpha1.TemplateReference]int, error) {
+	list := &v1alpha1.ExportersList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[v1alpha1.TemplateReference]int)
+	for _, instance := range list.Items {
+		for _, status := range instance.Status.Exployments {
+			if len(status.Template.Name) > 0 {
+				counts[status.Template]++
+			}
+		}
 	}
+	return counts, nil
 }
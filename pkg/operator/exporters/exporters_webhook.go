@@ -0,0 +1,29 @@
+package exporters
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/operator/base"
+)
+
+//+kubebuilder:webhook:path=/validate-udmire-cn-v1alpha1-exporters,mutating=false,failurePolicy=fail,sideEffects=None,groups=udmire.cn,resources=exporters,verbs=create;update,versions=v1alpha1,name=vexporters.udmire.cn,admissionReviewVersions=v1
+
+// SetupWebhookWithManager sets up the webhook validating the values of the exployments.
+func (r *ExportersReconciler) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Exporters{}).
+		WithValidator(base.NewValidator("Exporters", r.validate)).
+		Complete()
+}
+
+func (r *ExportersReconciler) validate(instance client.Object) field.ErrorList {
+	var errs field.ErrorList
+	exployments := field.NewPath("spec", "exployments")
+	for name, app := range instance.(*v1alpha1.Exporters).Spec.Exployments {
+		errs = append(errs, r.handler.Validate(app, exployments.Key(name))...)
+	}
+	return errs
+}
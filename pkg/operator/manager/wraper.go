@@ -8,9 +8,11 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/services"
 	udmirecnv1alpha1 "github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/utils"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -18,6 +20,8 @@ import (
 
 type CtrlManagerWraper interface {
 	Manager() ctrl.Manager
+	EventRecorder() record.EventRecorder
+	WebhooksEnabled() bool
 }
 
 type Config struct {
@@ -25,6 +29,7 @@ type Config struct {
 	MetricsAddress        string `yaml:"metric_address"`
 	ProbeAddress          string `yaml:"probe_address"`
 	EnabledLeaderElection bool   `yaml:"enable_leader_election"`
+	EnableWebhooks        bool   `yaml:"enable_webhooks"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
@@ -32,6 +37,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&c.MetricsAddress, "manager.metrics-address", ":8080", "The address the metric endpoint binds to.")
 	f.StringVar(&c.ProbeAddress, "manager.probe-address", ":8081", "The address the probe endpoint binds to.")
 	f.BoolVar(&c.EnabledLeaderElection, "manager.leader-elect", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	f.BoolVar(&c.EnableWebhooks, "manager.enable-webhooks", false, "Enable the validating webhooks served on the operator endpoint. The serving certificates are required.")
 }
 
 type managerWraper struct {
@@ -87,6 +93,14 @@ func (w *managerWraper) Manager() ctrl.Manager {
 	return w.Mgr
 }
 
+func (w *managerWraper) EventRecorder() record.EventRecorder {
+	return w.Mgr.GetEventRecorderFor(utils.DefaultManagedByValue)
+}
+
+func (w *managerWraper) WebhooksEnabled() bool {
+	return w.cfg.EnableWebhooks
+}
+
 func (r *managerWraper) run(ctx context.Context) error {
 	return r.startManager(ctx)
 }
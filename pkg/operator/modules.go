@@ -33,7 +33,11 @@ func (op *Operator) initTemplateStore() (serv services.Service, err error) {
 		return
 	}
 
-	store := category.New(op.Cfg.TemplateStore, util_log.Logger)
+	store, err := category.New(op.Cfg.TemplateStore, op.Registerer, util_log.Logger)
+	if err != nil {
+		return nil, err
+	}
+	store.SetManager(op.ControllerManager.Manager())
 	op.TemplateStore = store
 	return store, nil
 }
@@ -55,10 +59,13 @@ func (op *Operator) initAgentsController() (serv services.Service, err error) {
 		op.ControllerManager.Manager().GetClient(),
 		op.ControllerManager.Manager().GetScheme(),
 		op.TemplateStore.GetProvider(provider.Apps),
+		op.Registerer,
 		util_log.Logger)
 
 	ctrl.SetManager(op.ControllerManager.Manager())
 	ctrl.SetClusterNameProvider(op.InfoProviders.ClusterNameProvider())
+	ctrl.SetEventRecorder(op.ControllerManager.EventRecorder())
+	ctrl.SetWebhooksEnabled(op.ControllerManager.WebhooksEnabled())
 
 	return ctrl, nil
 }
@@ -69,9 +76,12 @@ func (op *Operator) initAppsController() (serv services.Service, err error) {
 		op.ControllerManager.Manager().GetScheme(),
 		op.Cfg.Apps,
 		op.TemplateStore.GetProvider(provider.Apps),
+		op.Registerer,
 		util_log.Logger)
 	ctrl.SetManager(op.ControllerManager.Manager())
 	ctrl.SetClusterNameProvider(op.InfoProviders.ClusterNameProvider())
+	ctrl.SetEventRecorder(op.ControllerManager.EventRecorder())
+	ctrl.SetWebhooksEnabled(op.ControllerManager.WebhooksEnabled())
 
 	return ctrl, nil
 }
@@ -82,9 +92,12 @@ func (op *Operator) initExportersController() (serv services.Service, err error)
 		op.ControllerManager.Manager().GetScheme(),
 		op.Cfg.Exporters,
 		op.TemplateStore.GetProvider(provider.Apps),
+		op.Registerer,
 		util_log.Logger)
 	ctrl.SetManager(op.ControllerManager.Manager())
 	ctrl.SetClusterNameProvider(op.InfoProviders.ClusterNameProvider())
+	ctrl.SetEventRecorder(op.ControllerManager.EventRecorder())
+	ctrl.SetWebhooksEnabled(op.ControllerManager.WebhooksEnabled())
 
 	return ctrl, nil
 }
@@ -94,9 +107,12 @@ func (op *Operator) initCapsulesController() (serv services.Service, err error)
 		op.ControllerManager.Manager().GetClient(),
 		op.ControllerManager.Manager().GetScheme(),
 		op.TemplateStore.GetProvider(provider.Capsules),
+		op.Registerer,
 		util_log.Logger)
 	ctrl.SetManager(op.ControllerManager.Manager())
 	ctrl.SetClusterNameProvider(op.InfoProviders.ClusterNameProvider())
+	ctrl.SetEventRecorder(op.ControllerManager.EventRecorder())
+	ctrl.SetWebhooksEnabled(op.ControllerManager.WebhooksEnabled())
 
 	return ctrl, nil
 }
@@ -115,13 +131,14 @@ func (op *Operator) SetupModuleManager() error {
 
 	// Add dependencies
 	deps := map[string][]string{
-		CtrlManager:   {},
-		InfoProviders: {CtrlManager},
-		Apps:          {TemplateStorage, InfoProviders},
-		Agents:        {TemplateStorage, InfoProviders},
-		Exporters:     {TemplateStorage, InfoProviders},
-		Capsules:      {TemplateStorage, InfoProviders},
-		All:           {Apps, Agents, Exporters, Capsules},
+		CtrlManager:     {},
+		TemplateStorage: {CtrlManager},
+		InfoProviders:   {CtrlManager},
+		Apps:            {TemplateStorage, InfoProviders},
+		Agents:          {TemplateStorage, InfoProviders},
+		Exporters:       {TemplateStorage, InfoProviders},
+		Capsules:        {TemplateStorage, InfoProviders},
+		All:             {Apps, Agents, Exporters, Capsules},
 	}
 
 	for mod, targets := range deps {
@@ -0,0 +1,37 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/udmire/observability-operator/pkg/operator/agents"
+	"github.com/udmire/observability-operator/pkg/operator/apps"
+	"github.com/udmire/observability-operator/pkg/operator/capsules"
+	"github.com/udmire/observability-operator/pkg/operator/exporters"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestControllers_metrics registers the metrics of all the controllers on the same registry, as the
+// operator does, the constant kind label of each controller must not collide with their labels.
+func TestControllers_metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := fake.NewClientBuilder().Build()
+	scheme := runtime.NewScheme()
+	logger := log.NewNopLogger()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("failed to register the metrics of the controllers: %v", r)
+		}
+	}()
+	agents.New(c, scheme, nil, reg, logger)
+	apps.New(c, scheme, apps.Config{}, nil, reg, logger)
+	exporters.New(c, scheme, exporters.Config{}, nil, reg, logger)
+	capsules.New(c, scheme, nil, reg, logger)
+
+	if _, err := reg.Gather(); err != nil {
+		t.Errorf("Gather() error = %v", err)
+	}
+}
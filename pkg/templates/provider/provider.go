@@ -12,12 +12,30 @@ const (
 	Capsules string = "capsules"
 )
 
+// ChangeListener is notified with the template name when a version of the template is loaded
+// into or unloaded from the provider.
+type ChangeListener func(name string)
+
 type TemplateProvider interface {
 	services.Service
 
 	SearchTemplates(name string) []*template.AppTemplate
 	GetTemplate(name, version string) *template.AppTemplate
 	GetLatestTemplate(name string) *template.AppTemplate
+	// ResolveTemplate returns the highest version of the template satisfying the version
+	// constraint, or the latest version of the template for an empty constraint.
+	ResolveTemplate(name, constraint string) *template.AppTemplate
+
+	// Subscribe registers the listener for the template changes.
+	Subscribe(listener ChangeListener)
+}
+
+// TemplateErrorProvider is implemented by the providers whose templates may fail to be provided
+// although loaded, e.g. the templates which cannot be composed with their bases.
+type TemplateErrorProvider interface {
+	// TemplateError returns why the template resolved for the version constraint cannot be
+	// provided, nil if it can or if no version of the template satisfies the constraint.
+	TemplateError(name, constraint string) error
 }
 
 type CategryTemplateProvider interface {
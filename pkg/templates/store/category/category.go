@@ -7,18 +7,32 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/cache"
 	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/services"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/templates/store"
+	"github.com/udmire/observability-operator/pkg/templates/store/cluster"
+	"github.com/udmire/observability-operator/pkg/templates/store/compose"
 	"github.com/udmire/observability-operator/pkg/templates/store/local"
+	"github.com/udmire/observability-operator/pkg/templates/store/oci"
+	"github.com/udmire/observability-operator/pkg/templates/store/s3"
 	"github.com/udmire/observability-operator/pkg/templates/store/sync"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+	"github.com/udmire/observability-operator/pkg/utils"
+	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 type Config struct {
 	BaseDirectory string                 `yaml:"base_directory"`
 	Categories    flagext.StringSliceCSV `yaml:"categories"`
 	Synchronize   sync.Config            `yaml:"sync"`
+	OCI           oci.Config             `yaml:"oci"`
+	Cluster       cluster.Config         `yaml:"cluster"`
+	Store         store.Config           `yaml:"store"`
+	Loader        template.LoaderConfig  `yaml:"loader"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
@@ -27,6 +41,10 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&c.Categories, "templates.store.category.categories", "Comma-separated list of template categories.")
 
 	c.Synchronize.RegisterFlags(f)
+	c.OCI.RegisterFlags(f)
+	c.Cluster.RegisterFlags(f)
+	c.Store.RegisterFlags(f)
+	c.Loader.RegisterFlags(f)
 }
 
 type CategoryStore struct {
@@ -38,34 +56,91 @@ type CategoryStore struct {
 	subservices        *services.Manager
 	subservicesWatcher *services.FailureWatcher
 
-	providers   map[string]provider.TemplateProvider
-	synchorizer provider.TemplatesSynchronizer
+	providers    map[string]provider.TemplateProvider
+	composed     map[string]provider.TemplateProvider
+	synchorizers []provider.TemplatesSynchronizer
 }
 
-func New(cfg Config, logger log.Logger) *CategoryStore {
+func New(cfg Config, reg prometheus.Registerer, logger log.Logger) (*CategoryStore, error) {
 	store := &CategoryStore{
 		cfg:    cfg,
 		logger: logger,
 	}
-	store.providers = buildProviders(cfg, logger)
+	providers, err := buildProviders(cfg, reg, logger)
+	if err != nil {
+		return nil, err
+	}
+	store.providers = providers
+	// the templates extending a base template are composed with their base of the same category.
+	store.composed = make(map[string]provider.TemplateProvider, len(providers))
+	for category, p := range providers {
+		store.composed[category] = compose.New(p, logger)
+	}
 	if store.cfg.Synchronize.Enabled {
-		store.synchorizer = sync.NewHttpSynchronizer(cfg.Synchronize, cfg.BaseDirectory, logger)
+		httpSync, err := sync.NewHttpSynchronizer(cfg.Synchronize, cfg.BaseDirectory, reg, logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create templates synchronizer")
+		}
+		store.synchorizers = append(store.synchorizers, httpSync)
+	}
+	if store.cfg.Synchronize.Git.Enabled {
+		store.synchorizers = append(store.synchorizers, sync.NewGitSynchronizer(cfg.Synchronize.Git, cfg.Categories, cfg.BaseDirectory, reg, logger))
 	}
 	store.BasicService = services.NewBasicService(store.starting, store.run, store.stopping)
-	return store
+	return store, nil
 }
 
-func buildProviders(cfg Config, logger log.Logger) map[string]provider.TemplateProvider {
+func buildProviders(cfg Config, reg prometheus.Registerer, logger log.Logger) (map[string]provider.TemplateProvider, error) {
 	providers := make(map[string]provider.TemplateProvider)
 
+	// the cache is shared by the stores of remote templates, so that the replicas of the operator
+	// don't all hit the remote storage.
+	cacheClient, err := cache.CreateClient("templates-store", cfg.Store.Cache, logger, reg)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create templates store cache")
+	}
+
 	for _, typ := range cfg.Categories {
+		directory := filepath.Join(cfg.BaseDirectory, typ)
+		if utils.StringsContain(cfg.Cluster.Categories, typ) {
+			providers[typ] = cluster.New(typ, logger)
+			continue
+		}
+		if utils.StringsContain(cfg.OCI.Categories, typ) {
+			providers[typ] = oci.New(cfg.OCI, typ, directory, logger)
+			continue
+		}
+		if utils.StringsContain(cfg.Store.S3.Categories, typ) {
+			s3Store, err := s3.New(cfg.Store.S3, typ, directory, cacheClient, logger)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to create s3 store for %s", typ)
+			}
+			providers[typ] = s3Store
+			continue
+		}
 		lc := local.Config{
-			Directory: filepath.Join(cfg.BaseDirectory, typ),
+			Directory: directory,
 		}
 		provider := local.New(lc, logger)
 		providers[typ] = provider
 	}
-	return providers
+
+	loader := template.NewTemplateLoaderWithConfig(cfg.Loader, logger)
+	for _, p := range providers {
+		if setter, ok := p.(interface{ SetLoader(template.TemplateLoader) }); ok {
+			setter.SetLoader(loader)
+		}
+	}
+	return providers, nil
+}
+
+// SetManager sets the manager of the providers watching the templates published in the cluster.
+func (r *CategoryStore) SetManager(mgr ctrl.Manager) {
+	for _, p := range r.providers {
+		if clusterStore, ok := p.(*cluster.Store); ok {
+			clusterStore.SetManager(mgr)
+		}
+	}
 }
 
 func (r *CategoryStore) starting(ctx context.Context) error {
@@ -76,8 +151,8 @@ func (r *CategoryStore) starting(ctx context.Context) error {
 		svcs = append(svcs, provider)
 	}
 
-	if r.synchorizer != nil {
-		svcs = append(svcs, r.synchorizer)
+	for _, synchorizer := range r.synchorizers {
+		svcs = append(svcs, synchorizer)
 	}
 
 	if r.subservices, err = services.NewManager(svcs...); err != nil {
@@ -115,5 +190,5 @@ func (r *CategoryStore) stopping(_ error) error {
 }
 
 func (r *CategoryStore) GetProvider(category string) provider.TemplateProvider {
-	return r.providers[category]
+	return r.composed[category]
 }
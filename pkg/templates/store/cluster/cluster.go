@@ -0,0 +1,341 @@
+package cluster
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+	"github.com/udmire/observability-operator/pkg/utils"
+)
+
+const (
+	Name = "cluster"
+)
+
+// Config configures the categories of templates published as Template resources in the cluster.
+type Config struct {
+	Categories flagext.StringSliceCSV `yaml:"categories"`
+}
+
+func (c *Config) RegisterFlags(f *flag.FlagSet) {
+	f.Var(&c.Categories, "templates.store.cluster.categories", "Comma-separated list of template categories published as Template resources in the cluster instead of the local directory.")
+}
+
+// Store serves the templates of the category published as Template resources. Every replica of
+// the operator watches the resources, while the status is reported by all of them alike.
+type Store struct {
+	*services.BasicService
+
+	category string
+	logger   log.Logger
+
+	mgr    ctrl.Manager
+	client client.Client
+	loader template.TemplateLoader
+
+	lock      sync.RWMutex
+	templates map[string]*template.AppTemplate // resource name & template
+	listeners []provider.ChangeListener
+}
+
+func New(category string, logger log.Logger) *Store {
+	store := &Store{
+		category:  category,
+		logger:    log.With(logger, "store", Name, "category", category),
+		loader:    template.NewTemplateLoader(logger),
+		templates: make(map[string]*template.AppTemplate),
+	}
+	store.BasicService = services.NewIdleService(store.starting, nil)
+	return store
+}
+
+func (s *Store) SetManager(mgr ctrl.Manager) {
+	s.mgr = mgr
+	s.client = mgr.GetClient()
+}
+
+// SetLoader sets the loader of the templates, e.g. with the configured limits.
+func (s *Store) SetLoader(loader template.TemplateLoader) {
+	s.loader = loader
+}
+
+func (s *Store) starting(ctx context.Context) error {
+	if s.mgr == nil {
+		return fmt.Errorf("no manager to watch the templates of %s", s.category)
+	}
+	if err := s.SetupWithManager(s.mgr); err != nil {
+		return err
+	}
+	if !s.mgr.GetCache().WaitForCacheSync(ctx) {
+		return fmt.Errorf("unable to sync the cache of templates")
+	}
+
+	// load the published templates before serving, the later changes are reconciled.
+	list := &v1alpha1.TemplateList{}
+	if err := s.client.List(ctx, list); err != nil {
+		return errors.Wrap(err, "unable to list templates")
+	}
+	for i := range list.Items {
+		if err := s.sync(ctx, &list.Items[i]); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to sync template", "name", list.Items[i].Name, "err", err)
+		}
+	}
+	return nil
+}
+
+//+kubebuilder:rbac:groups=udmire.cn,resources=templates,verbs=get;list;watch
+//+kubebuilder:rbac:groups=udmire.cn,resources=templates/status,verbs=get;update;patch
+
+// Reconcile loads the template published by the resource, or unloads it once the resource is
+// deleted or moved to another category.
+func (s *Store) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &v1alpha1.Template{}
+	if err := s.client.Get(ctx, req.NamespacedName, instance); apierrors.IsNotFound(err) {
+		s.unload(req.Name)
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		level.Error(s.logger).Log("msg", "unable to get Template", "name", req.Name, "err", err)
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, s.sync(ctx, instance)
+}
+
+// sync loads the template of the resource and reports the result in its status. The template
+// loaded last keeps being served if the resource turns invalid.
+func (s *Store) sync(ctx context.Context, instance *v1alpha1.Template) error {
+	if instance.Spec.Category != s.category {
+		s.unload(instance.Name)
+		return nil
+	}
+	if !instance.DeletionTimestamp.IsZero() {
+		s.unload(instance.Name)
+		return nil
+	}
+
+	app, err := s.build(ctx, instance)
+	if err == nil {
+		err = s.load(instance.Name, app)
+	}
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to load template", "name", instance.Name, "err", err)
+	}
+	return s.updateStatus(ctx, instance, err)
+}
+
+// build collects the files of the template and loads the template from memory.
+func (s *Store) build(ctx context.Context, instance *v1alpha1.Template) (*template.AppTemplate, error) {
+	files := map[string][]byte{}
+	for _, source := range instance.Spec.ConfigMaps {
+		cm := &corev1.ConfigMap{}
+		if err := s.client.Get(ctx, types.NamespacedName{Namespace: source.Namespace, Name: source.Name}, cm); err != nil {
+			return nil, errors.Wrapf(err, "unable to get ConfigMap %s/%s", source.Namespace, source.Name)
+		}
+		for key, content := range cm.Data {
+			files[path.Join(source.Path, key)] = []byte(content)
+		}
+		for key, content := range cm.BinaryData {
+			files[path.Join(source.Path, key)] = content
+		}
+	}
+	for file, content := range instance.Spec.Files {
+		files[file] = []byte(content)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files in template")
+	}
+
+	appVer := fmt.Sprintf("%s_%s", instance.Spec.Name, instance.Spec.Version)
+	return s.loader.LoadFiles(appVer, files)
+}
+
+// load serves the template of the resource, unless another resource published the version already.
+func (s *Store) load(resource string, app *template.AppTemplate) error {
+	s.lock.Lock()
+	for name, existing := range s.templates {
+		if name != resource && existing.Name == app.Name && existing.Version == app.Version {
+			s.lock.Unlock()
+			return fmt.Errorf("template %s %s is already published by %s", app.Name, app.Version, name)
+		}
+	}
+	previous := s.templates[resource]
+	s.templates[resource] = app
+	s.lock.Unlock()
+
+	level.Info(s.logger).Log("msg", "loaded template", "resource", resource, "template", app.Name, "version", app.Version)
+	if previous != nil && previous.Name != app.Name {
+		s.notify(previous.Name)
+	}
+	s.notify(app.Name)
+	return nil
+}
+
+func (s *Store) unload(resource string) {
+	s.lock.Lock()
+	app, exists := s.templates[resource]
+	delete(s.templates, resource)
+	s.lock.Unlock()
+
+	if exists {
+		level.Info(s.logger).Log("msg", "unloaded template", "resource", resource, "template", app.Name, "version", app.Version)
+		s.notify(app.Name)
+	}
+}
+
+func (s *Store) updateStatus(ctx context.Context, instance *v1alpha1.Template, err error) error {
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionTemplateLoaded,
+		Status:             metav1.ConditionTrue,
+		Reason:             v1alpha1.ReasonSucceeded,
+		ObservedGeneration: instance.Generation,
+	}
+	lastError := ""
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = v1alpha1.ReasonLoadFailed
+		condition.Message = err.Error()
+		lastError = err.Error()
+	}
+
+	existing := meta.FindStatusCondition(instance.Status.Conditions, condition.Type)
+	if existing != nil && existing.Status == condition.Status && existing.Message == condition.Message &&
+		instance.Status.ObservedGeneration == instance.Generation {
+		return nil
+	}
+
+	meta.SetStatusCondition(&instance.Status.Conditions, condition)
+	instance.Status.ObservedGeneration = instance.Generation
+	instance.Status.LastError = lastError
+	if err := s.client.Status().Update(ctx, instance); err != nil && !apierrors.IsConflict(err) {
+		level.Warn(s.logger).Log("msg", "unable to update status of Template", "name", instance.Name, "err", err)
+		return err
+	}
+	return nil
+}
+
+// templatesOfConfigMap maps the ConfigMap to the templates of the category referencing it.
+func (s *Store) templatesOfConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &v1alpha1.TemplateList{}
+	if err := s.client.List(ctx, list); err != nil {
+		level.Warn(s.logger).Log("msg", "unable to list templates", "err", err)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range list.Items {
+		if item.Spec.Category != s.category {
+			continue
+		}
+		for _, source := range item.Spec.ConfigMaps {
+			if source.Namespace == obj.GetNamespace() && source.Name == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: item.Name}})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller watching the templates with the Manager, the controller
+// runs on every replica as the templates are served by all of them.
+func (s *Store) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(fmt.Sprintf("templates-%s", s.category)).
+		For(&v1alpha1.Template{}).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(s.templatesOfConfigMap)).
+		WithOptions(controller.Options{NeedLeaderElection: pointer.Bool(false)}).
+		Complete(s)
+}
+
+func (s *Store) Subscribe(listener provider.ChangeListener) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *Store) notify(name string) {
+	s.lock.RLock()
+	listeners := s.listeners
+	s.lock.RUnlock()
+
+	for _, listener := range listeners {
+		listener(name)
+	}
+}
+
+func (s *Store) SearchTemplates(name string) []*template.AppTemplate {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var result []*template.AppTemplate
+	for _, at := range s.templates {
+		if strings.Contains(fmt.Sprintf("%s_%s", at.Name, at.Version), name) {
+			result = append(result, at)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name || result[i].Name == result[j].Name && result[i].Version < result[j].Version
+	})
+	return result
+}
+
+func (s *Store) GetTemplate(name, version string) *template.AppTemplate {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for _, at := range s.templates {
+		if at.Name == name && at.Version == version {
+			return at
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetLatestTemplate(name string) *template.AppTemplate {
+	return s.ResolveTemplate(name, "")
+}
+
+func (s *Store) ResolveTemplate(name, constraint string) *template.AppTemplate {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	versions := make(map[string]*template.AppTemplate)
+	for _, at := range s.templates {
+		if at.Name == name {
+			versions[at.Version] = at
+		}
+	}
+
+	candidates := make([]string, 0, len(versions))
+	for version := range versions {
+		candidates = append(candidates, version)
+	}
+	version, found := utils.ResolveVersion(candidates, constraint)
+	if !found {
+		return nil
+	}
+	return versions[version]
+}
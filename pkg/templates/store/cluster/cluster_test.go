@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/udmire/observability-operator/api/v1alpha1"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const configMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}
+`
+
+func newTemplate(resource, category, version string, files map[string]string, configMaps ...v1alpha1.TemplateConfigMapSource) *v1alpha1.Template {
+	return &v1alpha1.Template{
+		ObjectMeta: metav1.ObjectMeta{Name: resource, Generation: 1},
+		Spec: v1alpha1.TemplateSpec{
+			Category:   category,
+			Name:       "node-exporter",
+			Version:    version,
+			Files:      files,
+			ConfigMaps: configMaps,
+		},
+	}
+}
+
+func TestStore_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	source := v1alpha1.TemplateConfigMapSource{Name: "node-exporter-files", Namespace: "observability", Path: "comp"}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.Template{}).
+		WithObjects(
+			newTemplate("node-exporter-v1.0.0", "apps", "v1.0.0", map[string]string{"app_configmap.yaml": configMap}),
+			newTemplate("node-exporter-v1.1.0", "apps", "v1.1.0", map[string]string{"app_configmap.yaml": configMap}, source),
+			newTemplate("node-exporter-copy", "apps", "v1.0.0", map[string]string{"app_configmap.yaml": configMap}),
+			newTemplate("node-exporter-capsule", "capsules", "v2.0.0", map[string]string{"app_configmap.yaml": configMap}),
+			newTemplate("node-exporter-escape", "apps", "v3.0.0", map[string]string{"../app_configmap.yaml": configMap}),
+			newTemplate("node-exporter-oversize", "apps", "v4.0.0", map[string]string{"app_configmap.yaml": configMap + strings.Repeat("#", 1024)}),
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: source.Name, Namespace: source.Namespace},
+				Data:       map[string]string{"comp_configmap.yaml": configMap},
+			},
+		).Build()
+
+	store := New("apps", log.NewNopLogger())
+	store.client = c
+	store.SetLoader(template.NewTemplateLoaderWithConfig(template.LoaderConfig{MaxFileSize: 1024}, log.NewNopLogger()))
+	var changed []string
+	store.Subscribe(func(name string) {
+		changed = append(changed, name)
+	})
+
+	reconcile := func(resource string) {
+		t.Helper()
+		if _, err := store.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: resource}}); err != nil {
+			t.Fatalf("Reconcile(%s) error = %v", resource, err)
+		}
+	}
+	loaded := func(resource string) metav1.ConditionStatus {
+		t.Helper()
+		instance := &v1alpha1.Template{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: resource}, instance); err != nil {
+			t.Fatalf("Get(%s) error = %v", resource, err)
+		}
+		if condition := meta.FindStatusCondition(instance.Status.Conditions, v1alpha1.ConditionTemplateLoaded); condition != nil {
+			return condition.Status
+		}
+		return metav1.ConditionUnknown
+	}
+
+	for _, resource := range []string{"node-exporter-v1.0.0", "node-exporter-v1.1.0", "node-exporter-copy", "node-exporter-capsule", "node-exporter-escape", "node-exporter-oversize"} {
+		reconcile(resource)
+	}
+
+	tests := []struct {
+		resource string
+		want     metav1.ConditionStatus
+	}{
+		{resource: "node-exporter-v1.0.0", want: metav1.ConditionTrue},
+		{resource: "node-exporter-v1.1.0", want: metav1.ConditionTrue},
+		{resource: "node-exporter-copy", want: metav1.ConditionFalse},
+		{resource: "node-exporter-capsule", want: metav1.ConditionUnknown},
+		{resource: "node-exporter-escape", want: metav1.ConditionFalse},
+		{resource: "node-exporter-oversize", want: metav1.ConditionFalse},
+	}
+	for _, tt := range tests {
+		if got := loaded(tt.resource); got != tt.want {
+			t.Errorf("%s loaded = %s, want %s", tt.resource, got, tt.want)
+		}
+	}
+
+	latest := store.ResolveTemplate("node-exporter", "")
+	if latest == nil || latest.Version != "v1.1.0" {
+		t.Fatalf("ResolveTemplate() = %v, want v1.1.0", latest)
+	}
+	if latest.Workloads["comp"] == nil {
+		t.Errorf("component of the ConfigMap not loaded, got %v", latest.Workloads)
+	}
+	if got := store.GetTemplate("node-exporter", "v2.0.0"); got != nil {
+		t.Errorf("template of another category served, got %v", got)
+	}
+
+	requests := store.templatesOfConfigMap(context.Background(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: source.Name, Namespace: source.Namespace}})
+	if len(requests) != 1 || requests[0].Name != "node-exporter-v1.1.0" {
+		t.Errorf("templatesOfConfigMap() = %v", requests)
+	}
+
+	if err := c.Delete(context.Background(), newTemplate("node-exporter-v1.1.0", "apps", "v1.1.0", nil)); err != nil {
+		t.Fatal(err)
+	}
+	reconcile("node-exporter-v1.1.0")
+	if latest := store.ResolveTemplate("node-exporter", ""); latest == nil || latest.Version != "v1.0.0" {
+		t.Fatalf("ResolveTemplate() = %v, want v1.0.0", latest)
+	}
+
+	if want := []string{"node-exporter", "node-exporter", "node-exporter"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+}
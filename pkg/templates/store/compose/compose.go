@@ -0,0 +1,175 @@
+package compose
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+)
+
+// maxDepth is the most templates a template extends through its bases, which also stops the
+// templates extending each other.
+const maxDepth = 8
+
+// composite is the template composed of an overlay over the base it is composed with.
+type composite struct {
+	base   *template.AppTemplate
+	result *template.AppTemplate
+	err    error
+}
+
+// Provider composes the templates of the provider extending a base template with their bases,
+// the bases being resolved from the same provider. The templates are returned composed.
+type Provider struct {
+	provider.TemplateProvider
+
+	logger log.Logger
+
+	mutex      sync.Mutex
+	composites map[*template.AppTemplate]composite // overlay & its composite
+	dependents map[string]map[string]struct{}      // base name & names of the templates extending it
+	listeners  []provider.ChangeListener
+}
+
+func New(inner provider.TemplateProvider, logger log.Logger) *Provider {
+	p := &Provider{
+		TemplateProvider: inner,
+		logger:           logger,
+		composites:       make(map[*template.AppTemplate]composite),
+		dependents:       make(map[string]map[string]struct{}),
+	}
+	inner.Subscribe(p.changed)
+	return p
+}
+
+func (p *Provider) SearchTemplates(name string) []*template.AppTemplate {
+	var result []*template.AppTemplate
+	for _, app := range p.TemplateProvider.SearchTemplates(name) {
+		if composed := p.composed(app); composed != nil {
+			result = append(result, composed)
+		}
+	}
+	return result
+}
+
+func (p *Provider) GetTemplate(name, version string) *template.AppTemplate {
+	return p.composed(p.TemplateProvider.GetTemplate(name, version))
+}
+
+func (p *Provider) GetLatestTemplate(name string) *template.AppTemplate {
+	return p.composed(p.TemplateProvider.GetLatestTemplate(name))
+}
+
+func (p *Provider) ResolveTemplate(name, constraint string) *template.AppTemplate {
+	return p.composed(p.TemplateProvider.ResolveTemplate(name, constraint))
+}
+
+// Subscribe registers the listener for the template changes, the templates extending a changed
+// template are changed as well.
+func (p *Provider) Subscribe(listener provider.ChangeListener) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.listeners = append(p.listeners, listener)
+}
+
+// TemplateError returns why the template resolved for the constraint cannot be composed with its
+// base, nil if it can or if no version of the template satisfies the constraint.
+func (p *Provider) TemplateError(name, constraint string) error {
+	app := p.TemplateProvider.ResolveTemplate(name, constraint)
+	if app == nil {
+		return nil
+	}
+	_, err := p.compose(app, 0)
+	return err
+}
+
+// composed returns the template composed with its base, nil if it cannot be composed.
+func (p *Provider) composed(app *template.AppTemplate) *template.AppTemplate {
+	if app == nil {
+		return nil
+	}
+	result, err := p.compose(app, 0)
+	if err != nil {
+		level.Warn(p.logger).Log("msg", "cannot compose template with its base", "template", app.Name, "version", app.Version, "extends", app.Metadata.Extends, "err", err)
+		return nil
+	}
+	return result
+}
+
+func (p *Provider) compose(app *template.AppTemplate, depth int) (*template.AppTemplate, error) {
+	name, version, ok := app.Metadata.Base()
+	if !ok {
+		return app, nil
+	}
+	if depth >= maxDepth {
+		return nil, fmt.Errorf("%w: %s extends more than %d templates", template.ErrInvalidTemplate, app.Name, maxDepth)
+	}
+
+	p.mutex.Lock()
+	if _, ok := p.dependents[name]; !ok {
+		p.dependents[name] = map[string]struct{}{}
+	}
+	p.dependents[name][app.Name] = struct{}{}
+	p.mutex.Unlock()
+
+	base := p.TemplateProvider.ResolveTemplate(name, version)
+	var err error
+	if base != nil {
+		base, err = p.compose(base, depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.mutex.Lock()
+	cached, ok := p.composites[app]
+	p.mutex.Unlock()
+	if ok && cached.base == base {
+		return cached.result, cached.err
+	}
+
+	cached = composite{base: base}
+	if base == nil {
+		cached.err = fmt.Errorf("%w: base template %s of %s", template.ErrTemplateNotFound, app.Metadata.Extends, app.Name)
+	} else {
+		cached.result, cached.err = Compose(base, app)
+	}
+
+	p.mutex.Lock()
+	p.composites[app] = cached
+	p.mutex.Unlock()
+	return cached.result, cached.err
+}
+
+// changed drops the composites of the changed template, and notifies the listeners of the change
+// of the template and of the templates extending it.
+func (p *Provider) changed(name string) {
+	p.mutex.Lock()
+	changed := []string{name}
+	seen := map[string]bool{name: true}
+	for i := 0; i < len(changed); i++ {
+		for dependent := range p.dependents[changed[i]] {
+			if !seen[dependent] {
+				seen[dependent] = true
+				changed = append(changed, dependent)
+			}
+		}
+	}
+	for app := range p.composites {
+		if seen[app.Name] {
+			delete(p.composites, app)
+		}
+	}
+	listeners := p.listeners
+	p.mutex.Unlock()
+
+	for _, name := range changed {
+		level.Debug(p.logger).Log("msg", "template changed", "name", name)
+		for _, listener := range listeners {
+			listener(name)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package compose
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+	"sigs.k8s.io/yaml"
+)
+
+// fakeProvider provides the templates, resolving the constraints as exact versions.
+type fakeProvider struct {
+	services.Service
+
+	templates []*template.AppTemplate
+	listeners []provider.ChangeListener
+}
+
+func (f *fakeProvider) SearchTemplates(name string) []*template.AppTemplate {
+	var result []*template.AppTemplate
+	for _, app := range f.templates {
+		if strings.Contains(app.Name, name) {
+			result = append(result, app)
+		}
+	}
+	return result
+}
+
+func (f *fakeProvider) GetTemplate(name, version string) *template.AppTemplate {
+	for _, app := range f.templates {
+		if app.Name == name && app.Version == version {
+			return app
+		}
+	}
+	return nil
+}
+
+func (f *fakeProvider) GetLatestTemplate(name string) *template.AppTemplate {
+	return f.ResolveTemplate(name, "")
+}
+
+func (f *fakeProvider) ResolveTemplate(name, constraint string) *template.AppTemplate {
+	var latest *template.AppTemplate
+	for _, app := range f.templates {
+		if app.Name == name && (constraint == "" || app.Version == constraint) {
+			latest = app
+		}
+	}
+	return latest
+}
+
+func (f *fakeProvider) Subscribe(listener provider.ChangeListener) {
+	f.listeners = append(f.listeners, listener)
+}
+
+func (f *fakeProvider) load(app *template.AppTemplate) {
+	f.templates = append(f.templates, app)
+	for _, listener := range f.listeners {
+		listener(app.Name)
+	}
+}
+
+func file(name, content string) *template.TemplateFile {
+	return &template.TemplateFile{FileName: name, Content: []byte(content)}
+}
+
+func workload(name, version string, files ...*template.TemplateFile) *template.WorkloadTemplate {
+	return &template.WorkloadTemplate{TemplateBase: template.TemplateBase{Name: name, Version: version, TemplateFiles: files}}
+}
+
+func baseTemplate() *template.AppTemplate {
+	return &template.AppTemplate{
+		TemplateBase: template.TemplateBase{Name: "exporter", Version: "v1.0.0", TemplateFiles: []*template.TemplateFile{
+			file("exporter_configmap.yaml", "data:\n  config.yaml: base\n"),
+			file("exporter_dashboard.json", "{}"),
+		}},
+		Workloads: map[string]*template.WorkloadTemplate{
+			"exporter": workload("exporter", "v1.0.0", file("exporter_daemonset.yaml", `spec:
+  template:
+    spec:
+      containers:
+      - name: exporter
+        image: exporter:1.0.0
+        args: [--port=9100]
+      - name: sidecar
+        image: sidecar:1.0.0
+`)),
+			"extra": workload("extra", "v1.0.0", file("extra_deployment.yaml", "kind: Deployment")),
+		},
+		Metadata: &template.Metadata{
+			Description: "Exports the metrics.",
+			Parameters:  []template.Parameter{{Name: "port", Default: 9100}, {Name: "token"}},
+			Components:  map[string]template.Component{"exporter": {Description: "The exporter."}, "extra": {}},
+		},
+	}
+}
+
+func overlayTemplate(extends string, remove ...string) *template.AppTemplate {
+	return &template.AppTemplate{
+		TemplateBase: template.TemplateBase{Name: "blackbox", Version: "v2.0.0", TemplateFiles: []*template.TemplateFile{
+			file("exporter_dashboard.json", `{"title": "blackbox"}`),
+		}},
+		Workloads: map[string]*template.WorkloadTemplate{
+			"exporter": workload("exporter", "v2.0.0", file("exporter_daemonset.yaml", `spec:
+  template:
+    spec:
+      containers:
+      - name: exporter
+        image: blackbox:2.0.0
+`)),
+			"probe": workload("probe", "v2.0.0", file("probe_configmap.yaml", "kind: ConfigMap")),
+		},
+		Metadata: &template.Metadata{
+			Extends:          extends,
+			RemoveComponents: remove,
+			Parameters:       []template.Parameter{{Name: "port", Default: 9115}, {Name: "module"}},
+		},
+	}
+}
+
+func TestCompose(t *testing.T) {
+	app, err := Compose(baseTemplate(), overlayTemplate("exporter@v1.0.0", "extra"))
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+	if app.Name != "blackbox" || app.Version != "v2.0.0" {
+		t.Errorf("Compose() = %s_%s, want blackbox_v2.0.0", app.Name, app.Version)
+	}
+	if len(app.TemplateFiles) != 2 || string(app.TemplateFiles[1].Content) != `{"title": "blackbox"}` {
+		t.Errorf("Compose() files = %v, want the dashboard replaced", app.TemplateFiles)
+	}
+
+	if _, ok := app.Workloads["extra"]; ok || len(app.Workloads) != 2 || app.Workloads["probe"] == nil {
+		t.Fatalf("Compose() workloads = %v, want exporter & probe", app.Workloads)
+	}
+	daemonset := map[string]interface{}{}
+	if err := yaml.Unmarshal(app.Workloads["exporter"].TemplateFiles[0].Content, &daemonset); err != nil {
+		t.Fatal(err)
+	}
+	containers := daemonset["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"]
+	want := []interface{}{
+		map[string]interface{}{"name": "exporter", "image": "blackbox:2.0.0", "args": []interface{}{"--port=9100"}},
+		map[string]interface{}{"name": "sidecar", "image": "sidecar:1.0.0"},
+	}
+	if !reflect.DeepEqual(containers, want) {
+		t.Errorf("Compose() containers = %v, want %v", containers, want)
+	}
+
+	wantParams := []template.Parameter{{Name: "port", Default: 9115}, {Name: "token"}, {Name: "module"}}
+	if !reflect.DeepEqual(app.Metadata.Parameters, wantParams) {
+		t.Errorf("Compose() parameters = %v, want %v", app.Metadata.Parameters, wantParams)
+	}
+	if app.Metadata.Description != "Exports the metrics." || len(app.Metadata.Components) != 1 {
+		t.Errorf("Compose() metadata = %+v", app.Metadata)
+	}
+
+	if _, err := Compose(baseTemplate(), overlayTemplate("exporter@v1.0.0", "missing")); !errors.Is(err, template.ErrInvalidTemplate) {
+		t.Errorf("Compose() error = %v, want %v", err, template.ErrInvalidTemplate)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	inner := &fakeProvider{}
+	p := New(inner, log.NewNopLogger())
+	var changed []string
+	p.Subscribe(func(name string) {
+		changed = append(changed, name)
+	})
+
+	inner.load(overlayTemplate("exporter@v1.0.0"))
+	if app := p.GetTemplate("blackbox", "v2.0.0"); app != nil {
+		t.Errorf("GetTemplate() = %v, want nil without the base", app)
+	}
+	if err := p.TemplateError("blackbox", "v2.0.0"); !errors.Is(err, template.ErrTemplateNotFound) {
+		t.Errorf("TemplateError() = %v, want %v for the missing base", err, template.ErrTemplateNotFound)
+	}
+
+	inner.load(baseTemplate())
+	app := p.GetTemplate("blackbox", "v2.0.0")
+	if app == nil || len(app.Workloads) != 3 {
+		t.Fatalf("GetTemplate() = %v, want the composite", app)
+	}
+	if err := p.TemplateError("blackbox", "v2.0.0"); err != nil {
+		t.Errorf("TemplateError() = %v, want nil", err)
+	}
+	if again := p.ResolveTemplate("blackbox", ""); again != app {
+		t.Errorf("ResolveTemplate() = %p, want the cached composite %p", again, app)
+	}
+	if got := p.GetTemplate("exporter", "v1.0.0"); got != inner.templates[1] {
+		t.Errorf("GetTemplate() = %v, want the base unchanged", got)
+	}
+	if want := []string{"blackbox", "exporter", "blackbox"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+
+	cyclic := overlayTemplate("cyclic")
+	cyclic.Name = "cyclic"
+	inner.load(cyclic)
+	if app := p.GetTemplate("cyclic", "v2.0.0"); app != nil {
+		t.Errorf("GetTemplate() = %v, want nil for the template extending itself", app)
+	}
+	if err := p.TemplateError("cyclic", ""); !errors.Is(err, template.ErrInvalidTemplate) {
+		t.Errorf("TemplateError() = %v, want %v for the template extending itself", err, template.ErrInvalidTemplate)
+	}
+	if err := p.TemplateError("missing", ""); err != nil {
+		t.Errorf("TemplateError() = %v, want nil for the missing template", err)
+	}
+}
@@ -0,0 +1,163 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/udmire/observability-operator/pkg/apps/manifest"
+	"github.com/udmire/observability-operator/pkg/apps/specs"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+	"github.com/udmire/observability-operator/pkg/utils"
+)
+
+// Compose returns the template composed of the overlay over its base. The files of the overlay
+// strategic merge patch the files of the same name in the base, or replace them if rendered or
+// holding no known resource, the other files and components of the overlay are added.
+func Compose(base, overlay *template.AppTemplate) (*template.AppTemplate, error) {
+	removed := overlay.Metadata.RemoveComponents
+	for _, name := range removed {
+		if _, ok := base.Workloads[name]; !ok {
+			return nil, fmt.Errorf("%w: component %s to remove is not in the base template %s", template.ErrInvalidTemplate, name, base.Name)
+		}
+	}
+
+	result := &template.AppTemplate{
+		TemplateBase: template.TemplateBase{Name: overlay.Name, Version: overlay.Version},
+		Workloads:    map[string]*template.WorkloadTemplate{},
+		Metadata:     composeMetadata(base.Metadata, overlay.Metadata),
+		Schema:       overlay.Schema,
+		Revision:     overlay.Revision,
+	}
+	if result.Schema == nil {
+		result.Schema = base.Schema
+	}
+
+	var err error
+	if result.TemplateFiles, err = composeFiles(base.TemplateFiles, overlay.TemplateFiles); err != nil {
+		return nil, err
+	}
+	for name, work := range base.Workloads {
+		if utils.StringsContain(removed, name) {
+			continue
+		}
+		result.Workloads[name] = &template.WorkloadTemplate{
+			TemplateBase: template.TemplateBase{Name: name, Version: overlay.Version, TemplateFiles: work.TemplateFiles},
+		}
+	}
+	for name, work := range overlay.Workloads {
+		composed, ok := result.Workloads[name]
+		if !ok {
+			result.Workloads[name] = work
+			continue
+		}
+		if composed.TemplateFiles, err = composeFiles(composed.TemplateFiles, work.TemplateFiles); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func composeFiles(base, overlay []*template.TemplateFile) ([]*template.TemplateFile, error) {
+	patches := make(map[string]*template.TemplateFile, len(overlay))
+	for _, file := range overlay {
+		patches[file.FileName] = file
+	}
+
+	result := make([]*template.TemplateFile, 0, len(base)+len(overlay))
+	for _, file := range base {
+		patch, ok := patches[file.FileName]
+		if !ok {
+			result = append(result, file)
+			continue
+		}
+		patched, err := patchFile(file, patch)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, patched)
+		delete(patches, file.FileName)
+	}
+	for _, file := range overlay {
+		if _, ok := patches[file.FileName]; ok {
+			result = append(result, file)
+		}
+	}
+	return result, nil
+}
+
+// patchFile patches the file of the base template with the file of the overlay. The rendered
+// files cannot be patched before being rendered, so they are replaced as the unknown files are.
+func patchFile(base, patch *template.TemplateFile) (*template.TemplateFile, error) {
+	if strings.HasSuffix(base.FileName, template.RenderedFileSuffix) {
+		return patch, nil
+	}
+	schema, ok := manifest.Schema(base)
+	if !ok {
+		return patch, nil
+	}
+
+	content, err := specs.MergePatchManifest(base.Content, patch.Content, schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot patch %s: %v", template.ErrInvalidTemplate, base.FileName, err)
+	}
+	return &template.TemplateFile{FileName: base.FileName, Content: content}, nil
+}
+
+// composeMetadata returns the metadata of the overlay completed with the metadata of the base,
+// the parameters, capsules and components of the overlay overriding the ones of the same names.
+func composeMetadata(base, overlay *template.Metadata) *template.Metadata {
+	if base == nil {
+		return overlay
+	}
+
+	result := *overlay
+	if len(result.Description) == 0 {
+		result.Description = base.Description
+	}
+	if len(result.Maintainers) == 0 {
+		result.Maintainers = base.Maintainers
+	}
+	if len(result.OperatorVersion) == 0 {
+		result.OperatorVersion = base.OperatorVersion
+	}
+	result.Parameters = mergeNamed(base.Parameters, overlay.Parameters, func(p template.Parameter) string { return p.Name })
+	result.Capsules = mergeNamed(base.Capsules, overlay.Capsules, func(r template.Requirement) string { return r.Name })
+
+	result.Components = map[string]template.Component{}
+	for name, component := range base.Components {
+		if !utils.StringsContain(overlay.RemoveComponents, name) {
+			result.Components[name] = component
+		}
+	}
+	for name, component := range overlay.Components {
+		result.Components[name] = component
+	}
+	if len(result.Components) == 0 {
+		result.Components = nil
+	}
+	return &result
+}
+
+// mergeNamed returns the items of the base overridden by the items of the overlay of the same
+// names, followed by the other items of the overlay.
+func mergeNamed[T any](base, overlay []T, name func(T) string) []T {
+	overrides := make(map[string]T, len(overlay))
+	for _, item := range overlay {
+		overrides[name(item)] = item
+	}
+
+	var result []T
+	for _, item := range base {
+		if override, ok := overrides[name(item)]; ok {
+			item = override
+			delete(overrides, name(item))
+		}
+		result = append(result, item)
+	}
+	for _, item := range overlay {
+		if _, ok := overrides[name(item)]; ok {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/services"
+	"github.com/udmire/observability-operator/pkg/templates/provider"
 	"github.com/udmire/observability-operator/pkg/templates/template"
 	"github.com/udmire/observability-operator/pkg/utils"
 )
@@ -26,7 +27,7 @@ type Config struct {
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
-	f.StringVar(&c.Directory, "templates.store.directory", "/data/templates", "where the templates stored in local.")
+	f.StringVar(&c.Directory, "templates.store.directory", "/data/templates", "Deprecated: where the templates stored in local, use -templates.store.category.base-directory instead.")
 }
 
 type LocalStore struct {
@@ -40,6 +41,7 @@ type LocalStore struct {
 	lock      sync.Mutex
 	loader    template.TemplateLoader
 	templates map[string]*template.AppTemplate
+	listeners []provider.ChangeListener
 }
 
 func New(cfg Config, logger log.Logger) *LocalStore {
@@ -60,6 +62,11 @@ func New(cfg Config, logger log.Logger) *LocalStore {
 	return store
 }
 
+// SetLoader sets the loader of the templates, e.g. with the configured limits.
+func (l *LocalStore) SetLoader(loader template.TemplateLoader) {
+	l.loader = loader
+}
+
 func (l *LocalStore) Load() error {
 	err := fs.WalkDir(os.DirFS(l.cfg.Directory), ".", func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
@@ -70,7 +77,12 @@ func (l *LocalStore) Load() error {
 			if path == "." {
 				return nil
 			}
-			return fs.SkipDir // ignore all the content in sub folder.
+			if _, folder := l.templateName(path); folder {
+				if err := l.LoadTemplate(filepath.Join(l.cfg.Directory, path)); err != nil {
+					level.Warn(l.logger).Log("msg", "skipped invalid template folder", "dir", path, "err", err)
+				}
+			}
+			return fs.SkipDir // the content of the sub folders is loaded along with the template.
 		}
 
 		return l.LoadTemplate(filepath.Join(l.cfg.Directory, path))
@@ -83,9 +95,49 @@ func (l *LocalStore) Load() error {
 	return nil
 }
 
+// templateName returns the `<name>_<version>` of the template archive or folder at the path,
+// empty if the path holds no template.
+func (l *LocalStore) templateName(path string) (appVer string, folder bool) {
+	if appVer, _ = l.loader.TemplateName(path); len(appVer) > 0 {
+		return appVer, false
+	}
+	if base := filepath.Base(path); isTemplateFolder(base) {
+		return base, true
+	}
+	return "", false
+}
+
+// isTemplateFolder reports whether the folder is named `<name>_<version>` as the template archives
+// are, the hidden folders excluded.
+func isTemplateFolder(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	parts := strings.Split(name, "_")
+	return len(parts) == 2 && len(parts[0]) > 0 && len(parts[1]) > 0
+}
+
+// LoadTemplate loads the template of the archive or the unpacked folder at the path.
 func (l *LocalStore) LoadTemplate(path string) error {
-	appVer, _ := l.loader.TemplateName(path)
-	temp, err := l.loader.LoadTemplate(path)
+	appVer, folder := l.templateName(path)
+	if len(appVer) == 0 {
+		return nil
+	}
+
+	var temp *template.AppTemplate
+	var err error
+	if folder {
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			return statErr
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		temp, err = l.loader.LoadFolder(appVer, path)
+	} else {
+		temp, err = l.loader.LoadTemplate(path)
+	}
 	if err != nil {
 		return err
 	}
@@ -95,22 +147,48 @@ func (l *LocalStore) LoadTemplate(path string) error {
 	}
 
 	l.lock.Lock()
-	defer l.lock.Unlock()
 	l.templates[appVer] = temp
+	l.lock.Unlock()
+
+	l.notify(temp.Name)
 	return nil
 }
 
 func (l *LocalStore) UnloadTemplate(path string) {
-	appVer, _ := l.loader.TemplateName(path)
+	appVer, _ := l.templateName(path)
+	if len(appVer) == 0 {
+		return
+	}
 
 	l.lock.Lock()
-	defer l.lock.Unlock()
+	temp, exists := l.templates[appVer]
 	delete(l.templates, appVer)
+	l.lock.Unlock()
+
+	if exists {
+		l.notify(temp.Name)
+	}
 }
 
-func (l *LocalStore) SyncTemplates() {
+func (l *LocalStore) Subscribe(listener provider.ChangeListener) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
+	l.listeners = append(l.listeners, listener)
+}
+
+func (l *LocalStore) notify(name string) {
+	l.lock.Lock()
+	listeners := l.listeners
+	l.lock.Unlock()
+
+	level.Debug(l.logger).Log("msg", "template changed", "name", name)
+	for _, listener := range listeners {
+		listener(name)
+	}
+}
+
+// SyncTemplates loads the templates of the directory again, the templates take the lock as loaded.
+func (l *LocalStore) SyncTemplates() {
 	l.Load()
 }
 
@@ -119,6 +197,8 @@ func (l *LocalStore) ListAppTemplates() {
 }
 
 func (l *LocalStore) SearchTemplates(name string) []*template.AppTemplate {
+	l.lock.Lock()
+	defer l.lock.Unlock()
 	temps := l.templates
 	var result []*template.AppTemplate
 	for k, at := range temps {
@@ -130,6 +210,8 @@ func (l *LocalStore) SearchTemplates(name string) []*template.AppTemplate {
 }
 
 func (l *LocalStore) GetTemplate(name, version string) *template.AppTemplate {
+	l.lock.Lock()
+	defer l.lock.Unlock()
 	temps := l.templates
 	appVer := fmt.Sprintf("%s_%s", name, version)
 	if app, ok := temps[appVer]; ok {
@@ -140,25 +222,28 @@ func (l *LocalStore) GetTemplate(name, version string) *template.AppTemplate {
 }
 
 func (l *LocalStore) GetLatestTemplate(name string) *template.AppTemplate {
-	temps := l.templates
-	appPrefix := fmt.Sprintf("%s_", name)
+	return l.ResolveTemplate(name, "")
+}
 
-	var result *template.AppTemplate
-	var latest string
+func (l *LocalStore) ResolveTemplate(name, constraint string) *template.AppTemplate {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	temps := l.templates
 
-	for k, at := range temps {
-		if !strings.HasPrefix(k, appPrefix) {
-			continue
-		}
-		version := strings.TrimLeft(k, appPrefix)
-		if len(latest) <= 0 {
-			latest = version
-			result = at
-		} else if utils.IsNewerThan(version, latest) {
-			latest = version
-			result = at
+	versions := make(map[string]*template.AppTemplate)
+	for _, at := range temps {
+		if at.Name == name {
+			versions[at.Version] = at
 		}
 	}
 
-	return result
+	candidates := make([]string, 0, len(versions))
+	for version := range versions {
+		candidates = append(candidates, version)
+	}
+	version, found := utils.ResolveVersion(candidates, constraint)
+	if !found {
+		return nil
+	}
+	return versions[version]
 }
@@ -0,0 +1,204 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+)
+
+func TestLocalStore_Subscribe(t *testing.T) {
+	curDir, _ := os.Getwd()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app_v1.0.1.tar.gz")
+	copyFile(filepath.Join(curDir, "..", "..", "template", "app_v1.0.1.tar.gz"), path)
+
+	l := New(Config{Directory: dir}, log.NewNopLogger())
+	var changed []string
+	l.Subscribe(func(name string) {
+		changed = append(changed, name)
+	})
+
+	if err := l.LoadTemplate(path); err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	l.UnloadTemplate(path)
+	l.UnloadTemplate(path)
+
+	if want := []string{"app", "app"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+}
+
+func TestLocalStore_SyncTemplates(t *testing.T) {
+	dir := t.TempDir()
+	l := New(Config{Directory: dir}, log.NewNopLogger())
+	copyFile(filepath.Join("..", "..", "template", "app_v1.0.1.tar.gz"), filepath.Join(dir, "app_v1.0.1.tar.gz"))
+
+	done := make(chan struct{})
+	go func() {
+		l.SyncTemplates()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SyncTemplates() did not return")
+	}
+	if l.GetTemplate("app", "v1.0.1") == nil {
+		t.Errorf("GetTemplate() = nil, want the synced template")
+	}
+}
+
+func TestLocalStore_ResolveTemplate(t *testing.T) {
+	curDir, _ := os.Getwd()
+	dir := t.TempDir()
+	for _, file := range []string{"app_v1.0.1.tar.gz", "app_v1.0.2.tar.gz", "app_v1.0.1.1.zip"} {
+		copyFile(filepath.Join(curDir, "..", "..", "template", file), filepath.Join(dir, file))
+	}
+
+	l := New(Config{Directory: dir}, log.NewNopLogger())
+	if err := l.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		constraint string
+		want       string
+	}{
+		{name: "latest", constraint: "", want: "v1.0.2"},
+		{name: "exact", constraint: "v1.0.1.1", want: "v1.0.1.1"},
+		{name: "range", constraint: ">=1.0 <1.0.2", want: "v1.0.1"},
+		{name: "tilde", constraint: "~1.0", want: "v1.0.2"},
+		{name: "unsatisfied", constraint: "^2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := l.ResolveTemplate("app", tt.constraint)
+			if len(tt.want) == 0 {
+				if got != nil {
+					t.Errorf("ResolveTemplate() = %s, want nil", got.Version)
+				}
+				return
+			}
+			if got == nil || got.Version != tt.want {
+				t.Errorf("ResolveTemplate() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_isTemplateFolder(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "app_v1.0.0", want: true},
+		{name: "foo_1.2.0", want: true},
+		{name: "foo_v1.0.0-rc1", want: true},
+		{name: "node-exporter_v1.6.0-beta", want: true},
+		{name: "other", want: false},
+		{name: "_v1.0.0", want: false},
+		{name: "app_", want: false},
+		{name: "app_v1_0", want: false},
+		{name: ".app_v1.0.0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTemplateFolder(tt.name); got != tt.want {
+				t.Errorf("isTemplateFolder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalStore_folder(t *testing.T) {
+	dir := t.TempDir()
+	folder := filepath.Join(dir, "app_v1.0.0")
+	writeFile(t, filepath.Join(folder, "app_configmap.yaml"), "kind: ConfigMap")
+	writeFile(t, filepath.Join(folder, ".app_configmap.yaml.swp"), "binary")
+	writeFile(t, filepath.Join(dir, "other", "other_configmap.yaml"), "kind: ConfigMap")
+	writeFile(t, filepath.Join(dir, "bad_v1.0.0", "comp", "nested", "comp_configmap.yaml"), "kind: ConfigMap")
+	writeFile(t, filepath.Join(dir, "foo_1.2.0", "foo_configmap.yaml"), "kind: ConfigMap")
+	writeFile(t, filepath.Join(dir, "foo_v1.0.0-rc1", "foo_configmap.yaml"), "kind: ConfigMap")
+	copyFile(filepath.Join("..", "..", "template", "app_v1.0.1.tar.gz"), filepath.Join(dir, "app_v1.0.1.tar.gz"))
+
+	l := New(Config{Directory: dir}, log.NewNopLogger())
+	changed := make(chan string, 10)
+	l.Subscribe(func(name string) {
+		changed <- name
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := services.StartAndAwaitRunning(ctx, l); err != nil {
+		t.Fatalf("StartAndAwaitRunning() error = %v", err)
+	}
+	defer services.StopAndAwaitTerminated(context.Background(), l) //nolint:errcheck
+
+	app := l.GetTemplate("app", "v1.0.0")
+	if app == nil || len(app.TemplateFiles) != 1 {
+		t.Fatalf("GetTemplate() = %+v, want the template of the folder", app)
+	}
+	if got := l.SearchTemplates("app"); len(got) != 2 || l.GetTemplate("app", "v1.0.1") == nil {
+		t.Fatalf("SearchTemplates() = %v, want the folder and the archive", got)
+	}
+	if l.GetTemplate("foo", "1.2.0") == nil || l.GetTemplate("foo", "v1.0.0-rc1") == nil {
+		t.Fatalf("GetTemplate() = nil, want the folders loaded after the invalid one")
+	}
+	if l.GetTemplate("bad", "v1.0.0") != nil {
+		t.Errorf("GetTemplate() of the invalid folder != nil")
+	}
+	for len(changed) > 0 {
+		<-changed
+	}
+
+	// editing a file of a component reloads the template of the folder only.
+	writeFile(t, filepath.Join(folder, "comp", "comp_secret.yaml"), "kind: Secret")
+	waitChanged(t, changed, "app")
+	writeFile(t, filepath.Join(folder, "comp", "comp_secret.yaml"), "kind: Secret\nmetadata: {}")
+	waitChanged(t, changed, "app")
+	app = l.GetTemplate("app", "v1.0.0")
+	if comp := app.Workloads["comp"]; comp == nil || string(comp.TemplateFiles[0].Content) != "kind: Secret\nmetadata: {}" {
+		t.Errorf("GetTemplate() workloads = %v, want the edited component", app.Workloads)
+	}
+
+	if err := os.RemoveAll(folder); err != nil {
+		t.Fatal(err)
+	}
+	waitChanged(t, changed, "app")
+	deadline := time.Now().Add(5 * time.Second)
+	for l.GetTemplate("app", "v1.0.0") != nil && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if l.GetTemplate("app", "v1.0.0") != nil {
+		t.Errorf("GetTemplate() of the removed folder != nil")
+	}
+}
+
+func waitChanged(t *testing.T, changed chan string, want string) {
+	t.Helper()
+	select {
+	case name := <-changed:
+		if name != want {
+			t.Errorf("changed = %s, want %s", name, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("template %s not changed", want)
+	}
+}
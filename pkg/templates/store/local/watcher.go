@@ -2,13 +2,20 @@ package local
 
 import (
 	"context"
-	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/go-kit/log/level"
 )
 
+// reloadDelay is how long the changes in a template folder settle before the template is reloaded,
+// so that a template being edited or removed is not loaded half-way.
+const reloadDelay = 500 * time.Millisecond
+
 func (l *LocalStore) startup(ctx context.Context) error {
 	return l.Load()
 }
@@ -17,9 +24,38 @@ func (l *LocalStore) watching(ctx context.Context) error {
 	l.watcher, _ = fsnotify.NewWatcher()
 	l.watcher.Add(l.cfg.Directory)
 
+	entries, _ := os.ReadDir(l.cfg.Directory)
+	for _, entry := range entries {
+		if _, folder := l.templateName(entry.Name()); folder && entry.IsDir() {
+			l.watchFolder(filepath.Join(l.cfg.Directory, entry.Name()))
+		}
+	}
+
+	// the template folders changed since reloaded last.
+	pending := map[string]struct{}{}
+	reload := time.NewTimer(reloadDelay)
+	reload.Stop()
+	defer reload.Stop()
+
 	for {
 		select {
+		case <-ctx.Done():
+			return nil
 		case event := <-l.watcher.Events:
+			if folder, ok := l.templateFolderOf(event.Name); ok {
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				if event.Op&fsnotify.Create == fsnotify.Create {
+					if file, err := os.Stat(event.Name); err == nil && file.IsDir() {
+						l.watchFolder(event.Name)
+					}
+				}
+				pending[folder] = struct{}{}
+				reload.Reset(reloadDelay)
+				continue
+			}
+
 			if event.Op&fsnotify.Create == fsnotify.Create {
 				file, err := os.Stat(event.Name)
 				if err != nil {
@@ -28,24 +64,83 @@ func (l *LocalStore) watching(ctx context.Context) error {
 				}
 
 				if file.IsDir() {
-					level.Debug(l.logger).Log("msg", "watched directory change", "dir", event.Name)
-					continue
+					if _, folder := l.templateName(event.Name); !folder {
+						level.Debug(l.logger).Log("msg", "watched directory change", "dir", event.Name)
+						continue
+					}
+					l.watchFolder(event.Name)
 				}
 				l.LoadTemplate(event.Name)
 			}
 
 			if event.Op&fsnotify.Remove == fsnotify.Remove {
+				delete(pending, event.Name)
 				l.UnloadTemplate(event.Name)
 			}
 
 			if event.Op&fsnotify.Rename == fsnotify.Rename {
+				delete(pending, event.Name)
 				l.UnloadTemplate(event.Name)
 			}
+		case <-reload.C:
+			for folder := range pending {
+				l.reloadFolder(folder)
+			}
+			pending = map[string]struct{}{}
 		case err := <-l.watcher.Errors:
-			fmt.Println("error : ", err)
+			level.Error(l.logger).Log("msg", "failed to watch templates", "dir", l.cfg.Directory, "err", err)
+			return err
+		}
+	}
+}
+
+// templateFolderOf returns the template folder holding the path, if the path is in one.
+func (l *LocalStore) templateFolderOf(path string) (string, bool) {
+	rel, err := filepath.Rel(l.cfg.Directory, path)
+	if err != nil {
+		return "", false
+	}
+	parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+	if _, folder := l.templateName(parts[0]); !folder {
+		return "", false
+	}
+	return filepath.Join(l.cfg.Directory, parts[0]), true
+}
+
+// watchFolder watches the template folder along with its sub folders, fsnotify not being recursive.
+func (l *LocalStore) watchFolder(dir string) {
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
 			return err
 		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(entry.Name(), ".") {
+			return fs.SkipDir
+		}
+		return l.watcher.Add(path)
+	})
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "failed to watch template folder", "dir", dir, "err", err)
+	}
+}
+
+// reloadFolder reloads the template of the changed folder, the template is kept as loaded last if
+// the folder cannot be loaded.
+func (l *LocalStore) reloadFolder(dir string) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		l.UnloadTemplate(dir)
+		return
+	}
+	if err := l.LoadTemplate(dir); err != nil {
+		level.Warn(l.logger).Log("msg", "failed to reload template folder", "dir", dir, "err", err)
+		return
 	}
+	level.Debug(l.logger).Log("msg", "reloaded template folder", "dir", dir)
 }
 
 func (l *LocalStore) shutdown(_ error) error {
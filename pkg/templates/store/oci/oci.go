@@ -0,0 +1,354 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/templates/store/local"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+)
+
+const (
+	Name = "oci"
+
+	// BlobsDirectory is the folder under the category directory caching the pulled blobs.
+	BlobsDirectory = ".blobs"
+)
+
+// versionTagPattern matches the tags taken as the versions of the templates.
+var versionTagPattern = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*(-[0-9A-Za-z.-]+)?$`)
+
+// Config configures the templates pulled as OCI artifacts, `<address>/<category>/<name>:<version>`.
+type Config struct {
+	Categories flagext.StringSliceCSV `yaml:"categories"`
+	Address    string                 `yaml:"address"`
+	Templates  flagext.StringSliceCSV `yaml:"templates"`
+	Username   string                 `yaml:"username"`
+	Password   flagext.Secret         `yaml:"password"`
+	PlainHTTP  bool                   `yaml:"plain_http"`
+	Interval   time.Duration          `yaml:"interval"`
+	Timeout    time.Duration          `yaml:"timeout"`
+}
+
+func (c *Config) RegisterFlags(f *flag.FlagSet) {
+	f.Var(&c.Categories, "templates.store.oci.categories", "Comma-separated list of template categories pulled from the OCI registry instead of the local directory.")
+	f.StringVar(&c.Address, "templates.store.oci.address", "", "Address of the OCI registry, optionally followed by a repository prefix, e.g. registry.example.com/templates.")
+	f.Var(&c.Templates, "templates.store.oci.templates", "Comma-separated list of template names to pull. The templates are discovered from the registry catalog if empty.")
+	f.StringVar(&c.Username, "templates.store.oci.username", "", "Username to authenticate to the OCI registry.")
+	f.Var(&c.Password, "templates.store.oci.password", "Password to authenticate to the OCI registry.")
+	f.BoolVar(&c.PlainHTTP, "templates.store.oci.plain-http", false, "Access the OCI registry with plain HTTP instead of HTTPS.")
+	f.DurationVar(&c.Interval, "templates.store.oci.interval", 10*time.Minute, "Interval of pulling templates from the OCI registry.")
+	f.DurationVar(&c.Timeout, "templates.store.oci.timeout", 30*time.Second, "Timeout of the requests to the OCI registry.")
+}
+
+// Store serves the templates of the category pulled from the OCI registry. The archives are
+// cached in the category directory and served by a local store.
+type Store struct {
+	*services.BasicService
+
+	cfg      Config
+	category string
+	logger   log.Logger
+
+	directory string
+	prefix    string
+	client    *registryClient
+	loader    template.TemplateLoader
+	local     *local.LocalStore
+}
+
+func New(cfg Config, category, directory string, logger log.Logger) *Store {
+	host, prefix, _ := strings.Cut(cfg.Address, "/")
+	store := &Store{
+		cfg:       cfg,
+		category:  category,
+		logger:    log.With(logger, "store", Name, "category", category),
+		directory: directory,
+		prefix:    prefix,
+		client:    newRegistryClient(cfg, host),
+		loader:    template.NewTemplateLoader(logger),
+		local:     local.New(local.Config{Directory: directory}, logger),
+	}
+	store.BasicService = services.NewTimerService(cfg.Interval, store.starting, store.iterate, store.stopping)
+	return store
+}
+
+func (s *Store) starting(ctx context.Context) error {
+	if err := services.StartAndAwaitRunning(ctx, s.local); err != nil {
+		return err
+	}
+	return s.iterate(ctx)
+}
+
+// iterate synchronizes the templates, the cached templates are served if the registry is unavailable.
+func (s *Store) iterate(ctx context.Context) error {
+	if err := s.Synchronize(ctx); err != nil {
+		level.Warn(s.logger).Log("msg", "failed to pull templates from registry", "err", err)
+	}
+	return nil
+}
+
+func (s *Store) stopping(_ error) error {
+	return services.StopAndAwaitTerminated(context.Background(), s.local)
+}
+
+// Synchronize pulls the versions of the templates tagged in the registry, and removes the cached
+// templates whose tags no longer exist.
+func (s *Store) Synchronize(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Join(s.directory, BlobsDirectory), 0755); err != nil {
+		return err
+	}
+	names, err := s.templateNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	pulled := map[string]bool{}
+	failed := map[string]bool{}
+	for _, name := range names {
+		repository := path.Join(s.prefix, s.category, name)
+		tags, err := s.client.Tags(ctx, repository)
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "failed to list template versions", "repository", repository, "err", err)
+			failed[name] = true
+			continue
+		}
+
+		for _, tag := range tags {
+			if !versionTagPattern.MatchString(tag) {
+				continue
+			}
+			file, err := s.pull(ctx, repository, name, tag)
+			if err != nil {
+				level.Warn(s.logger).Log("msg", "failed to pull template", "repository", repository, "tag", tag, "err", err)
+				failed[name] = true
+				continue
+			}
+			pulled[file] = true
+		}
+	}
+
+	s.prune(pulled, failed)
+	return nil
+}
+
+// templateNames returns the configured template names, or the names of the category
+// repositories in the registry catalog.
+func (s *Store) templateNames(ctx context.Context) ([]string, error) {
+	if len(s.cfg.Templates) > 0 {
+		return s.cfg.Templates, nil
+	}
+
+	repositories, err := s.client.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	prefix := path.Join(s.prefix, s.category) + "/"
+	for _, repository := range repositories {
+		name := strings.TrimPrefix(repository, prefix)
+		if name == repository || strings.ContainsAny(name, "/_") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// pull caches the template archive tagged in the repository as the file `<name>_<tag><ext>`,
+// and returns the file name.
+func (s *Store) pull(ctx context.Context, repository, name, tag string) (string, error) {
+	manifest, err := s.client.Manifest(ctx, repository, tag)
+	if err != nil {
+		return "", err
+	}
+	layer, ext, err := archiveLayer(manifest)
+	if err != nil {
+		return "", err
+	}
+
+	file := fmt.Sprintf("%s_%s%s", name, tag, ext)
+	target := filepath.Join(s.directory, file)
+	if digestOf(target) == layer.Digest {
+		return file, nil
+	}
+
+	blob, err := s.fetchBlob(ctx, repository, layer.Digest)
+	if err != nil {
+		return "", err
+	}
+	if err := s.link(blob, target); err != nil {
+		return "", err
+	}
+
+	level.Info(s.logger).Log("msg", "pulled template", "repository", repository, "tag", tag, "digest", layer.Digest)
+	return file, s.local.LoadTemplate(target)
+}
+
+// fetchBlob downloads the blob unless it is cached already, and returns the path of the cached blob.
+func (s *Store) fetchBlob(ctx context.Context, repository, digest string) (string, error) {
+	blob := filepath.Join(s.directory, BlobsDirectory, strings.ReplaceAll(digest, ":", "-"))
+	if _, err := os.Stat(blob); err == nil {
+		return blob, nil
+	}
+
+	temp, err := os.CreateTemp(filepath.Join(s.directory, BlobsDirectory), "blob-*.temp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	if err := s.client.FetchBlob(ctx, repository, digest, temp); err != nil {
+		return "", err
+	}
+	if err := temp.Close(); err != nil {
+		return "", err
+	}
+	return blob, os.Rename(temp.Name(), blob)
+}
+
+// link copies the cached blob to the template file, replacing the file atomically.
+func (s *Store) link(blob, target string) error {
+	src, err := os.Open(blob)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	temp, err := os.CreateTemp(filepath.Join(s.directory, BlobsDirectory), "template-*.temp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	if _, err := io.Copy(temp, src); err != nil {
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), target)
+}
+
+// prune removes the cached templates not pulled, except for the templates failed to synchronize.
+func (s *Store) prune(pulled, failed map[string]bool) {
+	entries, err := os.ReadDir(s.directory)
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to read templates directory", "err", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || pulled[entry.Name()] {
+			continue
+		}
+		appVer, ext := s.loader.TemplateName(entry.Name())
+		name, _, _ := strings.Cut(appVer, "_")
+		if len(ext) == 0 || failed[name] {
+			continue
+		}
+
+		file := filepath.Join(s.directory, entry.Name())
+		if err := os.Remove(file); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to remove template", "file", entry.Name(), "err", err)
+			continue
+		}
+		s.local.UnloadTemplate(file)
+		level.Info(s.logger).Log("msg", "removed template", "file", entry.Name())
+	}
+}
+
+// archiveLayer returns the layer holding the template archive and the extension of the archive.
+func archiveLayer(manifest *Manifest) (Descriptor, string, error) {
+	for _, layer := range manifest.Layers {
+		title := strings.ToLower(layer.Annotations[AnnotationTitle])
+		for _, ext := range []string{template.EXT_ZIP, template.EXT_TGZ, template.EXT_TAR_GZ} {
+			if strings.HasSuffix(title, ext) {
+				return layer, ext, nil
+			}
+		}
+		switch {
+		case strings.HasSuffix(layer.MediaType, "+zip") || strings.HasSuffix(layer.MediaType, "/zip"):
+			return layer, template.EXT_ZIP, nil
+		case strings.HasSuffix(layer.MediaType, "tar+gzip"):
+			return layer, template.EXT_TAR_GZ, nil
+		}
+	}
+	return Descriptor{}, "", fmt.Errorf("no template archive in the layers of the manifest")
+}
+
+// digestOf returns the sha256 digest of the file, or empty if the file cannot be read.
+func digestOf(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return ""
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// SetLoader sets the loader of the templates, e.g. with the configured limits.
+func (s *Store) SetLoader(loader template.TemplateLoader) {
+	s.loader = loader
+	s.local.SetLoader(loader)
+}
+
+// SyncTemplates pulls the templates from the registry, the cached templates are reloaded as pulled.
+func (s *Store) SyncTemplates() {
+	s.iterate(context.Background())
+}
+
+func (s *Store) ListAppTemplates() {
+	s.local.ListAppTemplates()
+}
+
+func (s *Store) LoadTemplate(path string) error {
+	return s.local.LoadTemplate(path)
+}
+
+func (s *Store) UnloadTemplate(path string) {
+	s.local.UnloadTemplate(path)
+}
+
+func (s *Store) SearchTemplates(name string) []*template.AppTemplate {
+	return s.local.SearchTemplates(name)
+}
+
+func (s *Store) GetTemplate(name, version string) *template.AppTemplate {
+	return s.local.GetTemplate(name, version)
+}
+
+func (s *Store) GetLatestTemplate(name string) *template.AppTemplate {
+	return s.local.GetLatestTemplate(name)
+}
+
+func (s *Store) ResolveTemplate(name, constraint string) *template.AppTemplate {
+	return s.local.ResolveTemplate(name, constraint)
+}
+
+func (s *Store) Subscribe(listener provider.ChangeListener) {
+	s.local.Subscribe(listener)
+}
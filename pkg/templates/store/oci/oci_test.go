@@ -0,0 +1,173 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+var registryPathPattern = regexp.MustCompile(`^/v2/(.+)/(tags|manifests|blobs)/(.+)$`)
+
+// fakeRegistry is a stand-in of the registry serving the template archives as OCI artifacts.
+type fakeRegistry struct {
+	mutex    sync.Mutex
+	tags     map[string]map[string][]byte // repository & tag & archive
+	token    string
+	blobHits int
+}
+
+func (r *fakeRegistry) push(repository, tag string, archive []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.tags[repository] == nil {
+		r.tags[repository] = map[string][]byte{}
+	}
+	r.tags[repository][tag] = archive
+}
+
+func (r *fakeRegistry) untag(repository, tag string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.tags[repository], tag)
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if req.URL.Path == "/token" {
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": r.token})
+		return
+	}
+	if len(r.token) > 0 && req.Header.Get("Authorization") != "Bearer "+r.token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="registry"`, req.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if req.URL.Path == "/v2/_catalog" {
+		var repositories []string
+		for repository := range r.tags {
+			repositories = append(repositories, repository)
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]string{"repositories": repositories})
+		return
+	}
+
+	matches := registryPathPattern.FindStringSubmatch(req.URL.Path)
+	if matches == nil || r.tags[matches[1]] == nil {
+		http.NotFound(w, req)
+		return
+	}
+	tags := r.tags[matches[1]]
+	switch matches[2] {
+	case "tags":
+		list := []string{"latest"}
+		for tag := range tags {
+			list = append(list, tag)
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]string{"tags": list})
+	case "manifests":
+		archive, ok := tags[matches[3]]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", MediaTypeImageManifest)
+		_ = json.NewEncoder(w).Encode(Manifest{
+			MediaType: MediaTypeImageManifest,
+			Layers: []Descriptor{{
+				MediaType:   "application/vnd.oci.image.layer.v1.tar+gzip",
+				Digest:      digest(archive),
+				Size:        int64(len(archive)),
+				Annotations: map[string]string{AnnotationTitle: "template.tar.gz"},
+			}},
+		})
+	case "blobs":
+		for _, archive := range tags {
+			if digest(archive) == matches[3] {
+				r.blobHits++
+				_, _ = w.Write(archive)
+				return
+			}
+		}
+		http.NotFound(w, req)
+	}
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestStore_Synchronize(t *testing.T) {
+	curDir, _ := os.Getwd()
+	archive, err := os.ReadFile(filepath.Join(curDir, "..", "..", "template", "app_v1.0.1.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "anonymous"},
+		{name: "bearer token", token: "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &fakeRegistry{tags: map[string]map[string][]byte{}, token: tt.token}
+			registry.push("templates/apps/app", "v1.0.1", archive)
+			registry.push("templates/apps/app", "v1.0.2", archive)
+			registry.push("templates/capsules/app", "v2.0.0", archive)
+			server := httptest.NewServer(registry)
+			defer server.Close()
+
+			dir := t.TempDir()
+			cfg := Config{
+				Address:   strings.TrimPrefix(server.URL, "http://") + "/templates",
+				PlainHTTP: true,
+				Interval:  time.Hour,
+				Timeout:   time.Second,
+			}
+			store := New(cfg, "apps", dir, log.NewNopLogger())
+
+			if err := store.Synchronize(context.Background()); err != nil {
+				t.Fatalf("Synchronize() error = %v", err)
+			}
+			if got := store.ResolveTemplate("app", ""); got == nil || got.Version != "v1.0.2" {
+				t.Fatalf("ResolveTemplate() = %v, want v1.0.2", got)
+			}
+			if registry.blobHits != 1 {
+				t.Errorf("blob hits = %d, want the shared blob pulled once", registry.blobHits)
+			}
+
+			registry.untag("templates/apps/app", "v1.0.2")
+			if err := store.Synchronize(context.Background()); err != nil {
+				t.Fatalf("Synchronize() error = %v", err)
+			}
+			if got := store.ResolveTemplate("app", ""); got == nil || got.Version != "v1.0.1" {
+				t.Fatalf("ResolveTemplate() = %v, want v1.0.1", got)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "app_v1.0.2.tar.gz")); !os.IsNotExist(err) {
+				t.Errorf("untagged template not removed, err = %v", err)
+			}
+			if registry.blobHits != 1 {
+				t.Errorf("blob hits = %d, want the cached blob reused", registry.blobHits)
+			}
+		})
+	}
+}
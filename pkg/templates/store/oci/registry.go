@@ -0,0 +1,272 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	MediaTypeImageManifest  = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// AnnotationTitle holds the file name of the layer, as set by `oras push`.
+	AnnotationTitle = "org.opencontainers.image.title"
+)
+
+var (
+	linkPattern      = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+	challengePattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// Descriptor describes the content addressed by the digest.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is the image manifest of an artifact.
+type Manifest struct {
+	MediaType string       `json:"mediaType,omitempty"`
+	Config    Descriptor   `json:"config"`
+	Layers    []Descriptor `json:"layers"`
+}
+
+// registryClient is a client of the registry HTTP API V2, authenticating with basic auth or with
+// the bearer tokens of the token authentication specification.
+type registryClient struct {
+	scheme   string
+	host     string
+	username string
+	password string
+	client   *http.Client
+
+	mutex  sync.Mutex
+	tokens map[string]string // scope & token
+}
+
+func newRegistryClient(cfg Config, host string) *registryClient {
+	scheme := "https"
+	if cfg.PlainHTTP {
+		scheme = "http"
+	}
+	return &registryClient{
+		scheme:   scheme,
+		host:     host,
+		username: cfg.Username,
+		password: cfg.Password.String(),
+		client:   &http.Client{Timeout: cfg.Timeout},
+		tokens:   make(map[string]string),
+	}
+}
+
+// Catalog lists the repositories of the registry.
+func (c *registryClient) Catalog(ctx context.Context) ([]string, error) {
+	var repositories []string
+	err := c.paginate(ctx, "/v2/_catalog", "registry:catalog:*", func(body io.Reader) error {
+		var page struct {
+			Repositories []string `json:"repositories"`
+		}
+		if err := json.NewDecoder(body).Decode(&page); err != nil {
+			return err
+		}
+		repositories = append(repositories, page.Repositories...)
+		return nil
+	})
+	return repositories, err
+}
+
+// Tags lists the tags of the repository.
+func (c *registryClient) Tags(ctx context.Context, repository string) ([]string, error) {
+	var tags []string
+	err := c.paginate(ctx, fmt.Sprintf("/v2/%s/tags/list", repository), pullScope(repository), func(body io.Reader) error {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		if err := json.NewDecoder(body).Decode(&page); err != nil {
+			return err
+		}
+		tags = append(tags, page.Tags...)
+		return nil
+	})
+	return tags, err
+}
+
+// Manifest fetches the manifest of the repository referenced by the tag or the digest.
+func (c *registryClient) Manifest(ctx context.Context, repository, reference string) (*Manifest, error) {
+	resp, err := c.get(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repository, reference), pullScope(repository),
+		strings.Join([]string{MediaTypeImageManifest, MediaTypeDockerManifest}, ", "))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	manifest := &Manifest{}
+	if err := json.NewDecoder(resp.Body).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest of %s:%s: %w", repository, reference, err)
+	}
+	return manifest, nil
+}
+
+// FetchBlob writes the blob of the repository to the writer, verifying its sha256 digest.
+func (c *registryClient) FetchBlob(ctx context.Context, repository, digest string, w io.Writer) error {
+	algorithm, encoded, found := strings.Cut(digest, ":")
+	if !found || algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest %q", digest)
+	}
+
+	resp, err := c.get(ctx, fmt.Sprintf("/v2/%s/blobs/%s", repository, digest), pullScope(repository), "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, hash), resp.Body); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != encoded {
+		return fmt.Errorf("digest mismatch of blob %s, got sha256:%s", digest, actual)
+	}
+	return nil
+}
+
+// paginate requests the pages of the listing by following the Link headers.
+func (c *registryClient) paginate(ctx context.Context, path, scope string, decode func(io.Reader) error) error {
+	for len(path) > 0 {
+		resp, err := c.get(ctx, path, scope, "application/json")
+		if err != nil {
+			return err
+		}
+		err = decode(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("invalid response of %s: %w", path, err)
+		}
+
+		path = ""
+		if matches := linkPattern.FindStringSubmatch(resp.Header.Get("Link")); matches != nil {
+			path = matches[1]
+		}
+	}
+	return nil
+}
+
+func (c *registryClient) get(ctx context.Context, path, scope, accept string) (*http.Response, error) {
+	resp, err := c.do(ctx, path, scope, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.authorize(ctx, challenge, scope); err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(ctx, path, scope, accept); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status of %s: %s", path, resp.Status)
+	}
+	return resp, nil
+}
+
+func (c *registryClient) do(ctx context.Context, path, scope, accept string) (*http.Response, error) {
+	target, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	target.Scheme, target.Host = c.scheme, c.host
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", accept)
+	}
+
+	c.mutex.Lock()
+	token, ok := c.tokens[scope]
+	c.mutex.Unlock()
+	if ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if len(c.username) > 0 {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.client.Do(req)
+}
+
+// authorize fetches the bearer token for the scope from the realm of the challenge.
+func (c *registryClient) authorize(ctx context.Context, challenge, scope string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("unauthorized to access the registry %s", c.host)
+	}
+
+	attrs := map[string]string{}
+	for _, matches := range challengePattern.FindAllStringSubmatch(params, -1) {
+		attrs[matches[1]] = matches[2]
+	}
+	realm, err := url.Parse(attrs["realm"])
+	if err != nil || len(realm.Host) == 0 {
+		return fmt.Errorf("invalid realm of challenge %q", challenge)
+	}
+	query := realm.Query()
+	if service, ok := attrs["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if len(c.username) > 0 {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status of token request: %s", resp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("invalid token response: %w", err)
+	}
+	if len(token.Token) == 0 {
+		token.Token = token.AccessToken
+	}
+
+	c.mutex.Lock()
+	c.tokens[scope] = token.Token
+	c.mutex.Unlock()
+	return nil
+}
+
+func pullScope(repository string) string {
+	return fmt.Sprintf("repository:%s:pull", repository)
+}
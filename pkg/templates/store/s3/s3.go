@@ -0,0 +1,312 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/cache"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/udmire/observability-operator/pkg/templates/provider"
+	"github.com/udmire/observability-operator/pkg/templates/store/local"
+	"github.com/udmire/observability-operator/pkg/templates/store/sync"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+)
+
+const (
+	Name = "s3"
+)
+
+var objectKeyPattern = regexp.MustCompile("^" + sync.TemplateFilePattern + "$")
+
+// Config configures the templates stored as `<prefix>/<category>/<name>_v<version>.<ext>` objects
+// in a bucket of an S3 compatible object storage.
+type Config struct {
+	Categories      flagext.StringSliceCSV `yaml:"categories"`
+	Endpoint        string                 `yaml:"endpoint"`
+	Bucket          string                 `yaml:"bucket_name"`
+	Prefix          string                 `yaml:"prefix"`
+	Region          string                 `yaml:"region"`
+	AccessKeyID     string                 `yaml:"access_key_id"`
+	SecretAccessKey flagext.Secret         `yaml:"secret_access_key"`
+	Insecure        bool                   `yaml:"insecure"`
+	Interval        time.Duration          `yaml:"interval"`
+	Timeout         time.Duration          `yaml:"timeout"`
+	ListingTTL      time.Duration          `yaml:"listing_ttl"`
+	ArchiveTTL      time.Duration          `yaml:"archive_ttl"`
+}
+
+func (c *Config) RegisterFlags(f *flag.FlagSet) {
+	prefix := "templates.store.s3."
+	f.Var(&c.Categories, prefix+"categories", "Comma-separated list of template categories stored in the S3 bucket instead of the local directory.")
+	f.StringVar(&c.Endpoint, prefix+"endpoint", "", "The S3 bucket endpoint, e.g. s3.us-east-1.amazonaws.com or minio:9000.")
+	f.StringVar(&c.Bucket, prefix+"bucket-name", "", "The S3 bucket name storing the templates.")
+	f.StringVar(&c.Prefix, prefix+"prefix", "", "Prefix of the template objects in the bucket.")
+	f.StringVar(&c.Region, prefix+"region", "us-east-1", "The S3 region, used to sign the requests.")
+	f.StringVar(&c.AccessKeyID, prefix+"access-key-id", "", "The S3 access key ID, the requests are anonymous if empty.")
+	f.Var(&c.SecretAccessKey, prefix+"secret-access-key", "The S3 secret access key.")
+	f.BoolVar(&c.Insecure, prefix+"insecure", false, "If enabled, use http:// for the S3 endpoint instead of https://.")
+	f.DurationVar(&c.Interval, prefix+"interval", 10*time.Minute, "Interval of syncing templates from the S3 bucket.")
+	f.DurationVar(&c.Timeout, prefix+"timeout", 30*time.Second, "Timeout of the requests to the S3 bucket.")
+	f.DurationVar(&c.ListingTTL, prefix+"listing-ttl", 5*time.Minute, "How long to cache the template listings, if the template store cache is configured.")
+	f.DurationVar(&c.ArchiveTTL, prefix+"archive-ttl", 24*time.Hour, "How long to cache the template archives, if the template store cache is configured.")
+}
+
+// Store serves the templates of the category stored in the bucket. The listings and archives
+// are shared through the cache, if any, and the archives are mirrored in the category directory
+// and served by a local store.
+type Store struct {
+	*services.BasicService
+
+	cfg      Config
+	category string
+	logger   log.Logger
+
+	directory string
+	client    *bucketClient
+	cache     cache.Cache
+	loader    template.TemplateLoader
+	local     *local.LocalStore
+	pulled    map[string]string // template file & etag
+}
+
+// New creates the store of the category, the cache may be nil.
+func New(cfg Config, category, directory string, c cache.Cache, logger log.Logger) (*Store, error) {
+	client, err := newBucketClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{
+		cfg:       cfg,
+		category:  category,
+		logger:    log.With(logger, "store", Name, "category", category),
+		directory: directory,
+		client:    client,
+		cache:     c,
+		loader:    template.NewTemplateLoader(logger),
+		local:     local.New(local.Config{Directory: directory}, logger),
+		pulled:    make(map[string]string),
+	}
+	store.BasicService = services.NewTimerService(cfg.Interval, store.starting, store.iterate, store.stopping)
+	return store, nil
+}
+
+func (s *Store) starting(ctx context.Context) error {
+	if err := services.StartAndAwaitRunning(ctx, s.local); err != nil {
+		return err
+	}
+	return s.iterate(ctx)
+}
+
+// iterate synchronizes the templates, the mirrored templates are served if the bucket is unavailable.
+func (s *Store) iterate(ctx context.Context) error {
+	if err := s.Synchronize(ctx); err != nil {
+		level.Warn(s.logger).Log("msg", "failed to sync templates from bucket", "err", err)
+	}
+	return nil
+}
+
+func (s *Store) stopping(_ error) error {
+	return services.StopAndAwaitTerminated(context.Background(), s.local)
+}
+
+// Synchronize mirrors the template objects of the category, and removes the mirrored templates
+// whose objects no longer exist.
+func (s *Store) Synchronize(ctx context.Context) error {
+	objects, err := s.listObjects(ctx)
+	if err != nil {
+		return err
+	}
+
+	pulled := map[string]bool{}
+	failed := false
+	for _, object := range objects {
+		file := path.Base(object.Key)
+		if s.pulled[file] == object.ETag {
+			pulled[file] = true
+			continue
+		}
+
+		if err := s.pull(ctx, object, file); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to sync template", "key", object.Key, "err", err)
+			failed = true
+			continue
+		}
+		s.pulled[file] = object.ETag
+		pulled[file] = true
+	}
+
+	if !failed {
+		s.prune(pulled)
+	}
+	return nil
+}
+
+// listObjects lists the template objects of the category, from the cache if cached.
+func (s *Store) listObjects(ctx context.Context) ([]Object, error) {
+	prefix := path.Join(s.cfg.Prefix, s.category) + "/"
+	key := cacheKey("listing", s.cfg.Bucket, prefix)
+
+	if cached, ok := s.fetchCache(ctx, key); ok {
+		var objects []Object
+		if err := json.Unmarshal(cached, &objects); err == nil {
+			return objects, nil
+		}
+	}
+
+	listed, err := s.client.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var objects []Object
+	for _, object := range listed {
+		relative := strings.TrimPrefix(object.Key, path.Join(s.cfg.Prefix)+"/")
+		if !objectKeyPattern.MatchString(relative) || !strings.HasPrefix(relative, s.category+"/") {
+			continue
+		}
+		objects = append(objects, object)
+	}
+
+	if s.cache != nil {
+		if data, err := json.Marshal(objects); err == nil {
+			s.cache.StoreAsync(map[string][]byte{key: data}, s.cfg.ListingTTL)
+		}
+	}
+	return objects, nil
+}
+
+// pull mirrors the object in the category directory, reading the archive from the cache if cached.
+func (s *Store) pull(ctx context.Context, object Object, file string) error {
+	key := cacheKey("archive", s.cfg.Bucket, object.Key, object.ETag)
+	content, ok := s.fetchCache(ctx, key)
+	if !ok {
+		var err error
+		if content, err = s.client.GetObject(ctx, object.Key); err != nil {
+			return err
+		}
+		if s.cache != nil {
+			s.cache.StoreAsync(map[string][]byte{key: content}, s.cfg.ArchiveTTL)
+		}
+	}
+
+	target := filepath.Join(s.directory, file)
+	if existing, err := os.ReadFile(target); err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+
+	temp, err := os.CreateTemp(s.directory, fmt.Sprintf(".%s-*.temp", file))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	if _, err := temp.Write(content); err != nil {
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), target); err != nil {
+		return err
+	}
+
+	level.Info(s.logger).Log("msg", "synced template", "key", object.Key, "etag", object.ETag)
+	return s.local.LoadTemplate(target)
+}
+
+// prune removes the mirrored templates not in the bucket.
+func (s *Store) prune(pulled map[string]bool) {
+	entries, err := os.ReadDir(s.directory)
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to read templates directory", "err", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || pulled[entry.Name()] {
+			continue
+		}
+		if _, ext := s.loader.TemplateName(entry.Name()); len(ext) == 0 {
+			continue
+		}
+
+		file := filepath.Join(s.directory, entry.Name())
+		if err := os.Remove(file); err != nil {
+			level.Warn(s.logger).Log("msg", "failed to remove template", "file", entry.Name(), "err", err)
+			continue
+		}
+		delete(s.pulled, entry.Name())
+		s.local.UnloadTemplate(file)
+		level.Info(s.logger).Log("msg", "removed template", "file", entry.Name())
+	}
+}
+
+func (s *Store) fetchCache(ctx context.Context, key string) ([]byte, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+	data, ok := s.cache.Fetch(ctx, []string{key})[key]
+	return data, ok
+}
+
+// cacheKey builds the key of the cache entry, hashing the parts to satisfy the key restrictions
+// of memcached.
+func cacheKey(kind string, parts ...string) string {
+	return fmt.Sprintf("templates:s3:%s:%s", kind, hashHex([]byte(strings.Join(parts, "\x00"))))
+}
+
+// SetLoader sets the loader of the templates, e.g. with the configured limits.
+func (s *Store) SetLoader(loader template.TemplateLoader) {
+	s.loader = loader
+	s.local.SetLoader(loader)
+}
+
+// SyncTemplates mirrors the templates from the bucket, the mirrored templates are reloaded as written.
+func (s *Store) SyncTemplates() {
+	s.iterate(context.Background())
+}
+
+func (s *Store) ListAppTemplates() {
+	s.local.ListAppTemplates()
+}
+
+func (s *Store) LoadTemplate(path string) error {
+	return s.local.LoadTemplate(path)
+}
+
+func (s *Store) UnloadTemplate(path string) {
+	s.local.UnloadTemplate(path)
+}
+
+func (s *Store) SearchTemplates(name string) []*template.AppTemplate {
+	return s.local.SearchTemplates(name)
+}
+
+func (s *Store) GetTemplate(name, version string) *template.AppTemplate {
+	return s.local.GetTemplate(name, version)
+}
+
+func (s *Store) GetLatestTemplate(name string) *template.AppTemplate {
+	return s.local.GetLatestTemplate(name)
+}
+
+func (s *Store) ResolveTemplate(name, constraint string) *template.AppTemplate {
+	return s.local.ResolveTemplate(name, constraint)
+}
+
+func (s *Store) Subscribe(listener provider.ChangeListener) {
+	s.local.Subscribe(listener)
+}
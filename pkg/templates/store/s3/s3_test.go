@@ -0,0 +1,136 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/cache"
+)
+
+// fakeBucket is a stand-in of the S3 API serving the objects of a bucket in the path style.
+type fakeBucket struct {
+	mutex   sync.Mutex
+	name    string
+	objects map[string][]byte
+	hits    int
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.hits++
+
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(req.URL.Path, "/"+b.name)
+	if len(key) == 0 && req.URL.Query().Get("list-type") == "2" {
+		result := listBucketResult{}
+		for key, content := range b.objects {
+			if strings.HasPrefix(key, req.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, Object{Key: key, ETag: etag(content), Size: int64(len(content))})
+			}
+		}
+		_ = xml.NewEncoder(w).Encode(result)
+		return
+	}
+
+	content, ok := b.objects[strings.TrimPrefix(key, "/")]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	_, _ = w.Write(content)
+}
+
+func etag(content []byte) string {
+	sum := md5.Sum(content)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func TestStore_Synchronize(t *testing.T) {
+	curDir, _ := os.Getwd()
+	archive, err := os.ReadFile(filepath.Join(curDir, "..", "..", "template", "app_v1.0.1.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bucket := &fakeBucket{name: "templates", objects: map[string][]byte{
+		"prod/apps/app_v1.0.1.tar.gz":     archive,
+		"prod/apps/app_v1.0.2.tar.gz":     archive,
+		"prod/apps/README.md":             []byte("not a template"),
+		"prod/capsules/app_v2.0.0.tar.gz": archive,
+	}}
+	server := httptest.NewServer(bucket)
+	defer server.Close()
+
+	cfg := Config{
+		Endpoint:    strings.TrimPrefix(server.URL, "http://"),
+		Bucket:      "templates",
+		Prefix:      "prod",
+		Region:      "us-east-1",
+		AccessKeyID: "access",
+		Insecure:    true,
+		Interval:    time.Hour,
+		Timeout:     time.Second,
+		ListingTTL:  time.Hour,
+		ArchiveTTL:  time.Hour,
+	}
+	_ = cfg.SecretAccessKey.Set("secret")
+	sharedCache := cache.NewMockCache()
+
+	// the replicas of the operator share the listings and archives through the cache.
+	for i, want := range []int{3, 0} {
+		dir := t.TempDir()
+		store, err := New(cfg, "apps", dir, sharedCache, log.NewNopLogger())
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		bucket.hits = 0
+		if err := store.Synchronize(context.Background()); err != nil {
+			t.Fatalf("Synchronize() error = %v", err)
+		}
+		if bucket.hits != want {
+			t.Errorf("replica %d: bucket hits = %d, want %d", i, bucket.hits, want)
+		}
+		if got := store.ResolveTemplate("app", ""); got == nil || got.Version != "v1.0.2" {
+			t.Fatalf("replica %d: ResolveTemplate() = %v, want v1.0.2", i, got)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "README.md")); !os.IsNotExist(err) {
+			t.Errorf("replica %d: unexpected object synced, err = %v", i, err)
+		}
+	}
+
+	dir := t.TempDir()
+	store, err := New(cfg, "apps", dir, nil, log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := store.Synchronize(context.Background()); err != nil {
+		t.Fatalf("Synchronize() error = %v", err)
+	}
+	delete(bucket.objects, "prod/apps/app_v1.0.2.tar.gz")
+	if err := store.Synchronize(context.Background()); err != nil {
+		t.Fatalf("Synchronize() error = %v", err)
+	}
+	if got := store.ResolveTemplate("app", ""); got == nil || got.Version != "v1.0.1" {
+		t.Fatalf("ResolveTemplate() = %v, want v1.0.1", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app_v1.0.2.tar.gz")); !os.IsNotExist(err) {
+		t.Errorf("deleted template not removed, err = %v", err)
+	}
+}
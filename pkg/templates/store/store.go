@@ -8,20 +8,25 @@ import (
 	"github.com/grafana/dskit/cache"
 	"github.com/udmire/observability-operator/pkg/templates/provider"
 	"github.com/udmire/observability-operator/pkg/templates/store/local"
+	"github.com/udmire/observability-operator/pkg/templates/store/s3"
 )
 
 var supportedCacheBackends = []string{cache.BackendMemcached, cache.BackendRedis}
 
-// Config configures a rule store.
+// Config configures the object storage of templates.
 type Config struct {
+	// Deprecated: the templates are stored in the category folders of the base directory of the
+	// category store. Kept so that the existing flags and configurations are still accepted.
 	Local local.Config `yaml:"local"`
+	S3    s3.Config    `yaml:"s3"`
 
-	// Cache holds the configuration used for the ruler storage cache.
+	// Cache holds the configuration used for the template storage cache.
 	Cache cache.BackendConfig `yaml:"cache"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.Local.RegisterFlags(f)
+	c.S3.RegisterFlags(f)
 
 	prefix := "templates.store."
 	f.StringVar(&c.Cache.Backend, prefix+"cache.backend", "", fmt.Sprintf("Backend for template storage cache, if not empty. The cache is supported for any storage backend except %q. Supported values: %s.", local.Name, strings.Join(supportedCacheBackends, ", ")))
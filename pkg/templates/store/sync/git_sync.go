@@ -0,0 +1,339 @@
+package sync
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// GitDirectory is the folder under the store path holding the bare clone of the repository.
+	GitDirectory = ".git-sync"
+)
+
+var templateFileRegex = regexp.MustCompile("^" + TemplateFilePattern + "$")
+
+// GitConfig configures the templates synchronized from a git repository.
+type GitConfig struct {
+	Enabled    bool                   `yaml:"enabled"`
+	Repository string                 `yaml:"repository"`
+	Reference  string                 `yaml:"reference"`
+	Paths      flagext.StringSliceCSV `yaml:"paths"`
+	Interval   time.Duration          `yaml:"interval"`
+	Timeout    time.Duration          `yaml:"timeout"`
+}
+
+func (c *GitConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.Enabled, "templates.store.sync.git.enabled", false, "Weather syncing templates from a git repository or not. Requires the git executable in the PATH.")
+	f.StringVar(&c.Repository, "templates.store.sync.git.repository", "", "URL of the git repository holding the templates.")
+	f.StringVar(&c.Reference, "templates.store.sync.git.reference", "HEAD", "Branch or tag of the git repository to sync.")
+	f.Var(&c.Paths, "templates.store.sync.git.paths", "Comma-separated list of <category>:<path> of the template folders in the repository. A category defaults to the folder of its name at the root.")
+	f.DurationVar(&c.Interval, "templates.store.sync.git.interval", 10*time.Minute, "Interval of syncing templates from the git repository.")
+	f.DurationVar(&c.Timeout, "templates.store.sync.git.timeout", 5*time.Minute, "Timeout of syncing templates from the git repository.")
+}
+
+// treeEntry is an entry listed by git ls-tree.
+type treeEntry struct {
+	typ  string
+	oid  string
+	name string
+}
+
+type gitSync struct {
+	*services.BasicService
+
+	cfg    GitConfig
+	logger log.Logger
+
+	storePath string
+	gitDir    string
+	paths     map[string]string // category & path in the repository
+
+	mutex     sync.Mutex
+	revision  string
+	templates map[string]string // template path & object id of its source
+
+	revisionInfo *prometheus.GaugeVec
+}
+
+// NewGitSynchronizer creates the synchronizer of the templates of the categories in the repository.
+// The templates are either archives or folders named `<name>_v<version>`, archived when synced.
+func NewGitSynchronizer(cfg GitConfig, categories []string, storePath string, reg prometheus.Registerer, logger log.Logger) *gitSync {
+	paths := make(map[string]string, len(categories))
+	for _, category := range categories {
+		paths[category] = category
+	}
+	for _, item := range cfg.Paths {
+		category, dir, found := strings.Cut(item, ":")
+		if _, ok := paths[category]; !found || !ok {
+			level.Warn(logger).Log("msg", "ignored invalid path of templates category", "path", item)
+			continue
+		}
+		paths[category] = strings.Trim(path.Clean(dir), "/")
+	}
+
+	sync := &gitSync{
+		cfg:       cfg,
+		logger:    log.With(logger, "repository", cfg.Repository),
+		storePath: storePath,
+		gitDir:    filepath.Join(storePath, GitDirectory),
+		paths:     paths,
+		templates: make(map[string]string),
+
+		revisionInfo: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "observperator",
+			Name:      "templates_sync_revision_info",
+			Help:      "The revision of the templates synced from the git repository.",
+		}, []string{"repository", "revision"}),
+	}
+	sync.BasicService = services.NewTimerService(cfg.Interval, sync.Synchronize, sync.Synchronize, nil)
+	return sync
+}
+
+// Revision returns the commit the templates were synced from last.
+func (s *gitSync) Revision() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.revision
+}
+
+func (s *gitSync) Synchronize(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	revision, err := s.fetch(ctx)
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to fetch templates repository", "err", err)
+		return err
+	}
+
+	synced := map[string]string{}
+	for category, dir := range s.paths {
+		entries, err := s.listTree(ctx, revision, dir)
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "failed to list templates", "category", category, "err", err)
+			return err
+		}
+
+		for _, entry := range entries {
+			file := templateFile(category, entry)
+			if len(file) == 0 {
+				continue
+			}
+			name := path.Join(category, file)
+			if s.templates[name] != entry.oid {
+				if err := s.buildTemplate(ctx, entry, category, file, revision); err != nil {
+					level.Warn(s.logger).Log("msg", "failed to build template", "template", name, "err", err)
+					continue
+				}
+			}
+			synced[name] = entry.oid
+		}
+	}
+
+	for name := range s.templates {
+		if _, ok := synced[name]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.storePath, name)); err != nil && !os.IsNotExist(err) {
+			level.Warn(s.logger).Log("msg", "cannot remove template.", "name", name, "err", err)
+		}
+	}
+	s.templates = synced
+
+	if s.revision != revision {
+		level.Info(s.logger).Log("msg", "synced templates", "revision", revision, "templates", len(synced))
+	}
+	s.revision = revision
+	s.revisionInfo.Reset()
+	s.revisionInfo.WithLabelValues(s.cfg.Repository, revision).Set(1)
+	return nil
+}
+
+// fetch fetches the reference into the bare clone, and returns the commit fetched.
+func (s *gitSync) fetch(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(s.gitDir, "HEAD")); os.IsNotExist(err) {
+		if err := os.MkdirAll(s.gitDir, 0755); err != nil {
+			return "", err
+		}
+		if err := s.git(ctx, nil, "init", "--bare", "--quiet"); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.git(ctx, nil, "fetch", "--quiet", "--force", "--depth", "1", s.cfg.Repository, s.cfg.Reference); err != nil {
+		return "", err
+	}
+	out := &bytes.Buffer{}
+	if err := s.git(ctx, out, "rev-parse", "FETCH_HEAD^{commit}"); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// listTree lists the entries of the folder at the revision, none if the folder doesn't exist.
+func (s *gitSync) listTree(ctx context.Context, revision, dir string) ([]treeEntry, error) {
+	tree := revision
+	if len(dir) > 0 && dir != "." {
+		tree = fmt.Sprintf("%s:%s", revision, dir)
+		out := &bytes.Buffer{}
+		if err := s.git(ctx, out, "ls-tree", revision, "--", dir); err != nil || out.Len() == 0 {
+			return nil, err
+		}
+	}
+
+	out := &bytes.Buffer{}
+	if err := s.git(ctx, out, "ls-tree", tree); err != nil {
+		return nil, err
+	}
+
+	var entries []treeEntry
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		// <mode> SP <type> SP <object> TAB <file>
+		info, name, found := strings.Cut(scanner.Text(), "\t")
+		fields := strings.Fields(info)
+		if !found || len(fields) != 3 {
+			continue
+		}
+		entries = append(entries, treeEntry{typ: fields[1], oid: fields[2], name: name})
+	}
+	return entries, nil
+}
+
+// buildTemplate writes the template archive of the entry to the category folder, recording the
+// revision in the comment of the archive.
+func (s *gitSync) buildTemplate(ctx context.Context, entry treeEntry, category, file, revision string) error {
+	temp, err := os.CreateTemp(s.storePath, fmt.Sprintf("%s.temp", file))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	switch {
+	case entry.typ == "tree":
+		err = s.archiveTree(ctx, temp, entry, revision)
+	case strings.HasSuffix(strings.ToLower(file), ".zip"):
+		err = s.copyZip(ctx, temp, entry, revision)
+	default:
+		err = s.copyTarGz(ctx, temp, entry, revision)
+	}
+	if err != nil {
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(s.storePath, category), 0755); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), filepath.Join(s.storePath, category, file))
+}
+
+// archiveTree archives the folder of the template with the folder as the root.
+func (s *gitSync) archiveTree(ctx context.Context, w io.Writer, entry treeEntry, revision string) error {
+	gzWriter, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+	gzWriter.Comment = revision
+	if err := s.git(ctx, gzWriter, "archive", "--format=tar", fmt.Sprintf("--prefix=%s/", entry.name), entry.oid); err != nil {
+		return err
+	}
+	return gzWriter.Close()
+}
+
+// copyTarGz copies the tar.gz archive, recompressing it with the revision as the comment.
+func (s *gitSync) copyTarGz(ctx context.Context, w io.Writer, entry treeEntry, revision string) error {
+	blob := &bytes.Buffer{}
+	if err := s.git(ctx, blob, "cat-file", "blob", entry.oid); err != nil {
+		return err
+	}
+	gzReader, err := gzip.NewReader(blob)
+	if err != nil {
+		return err
+	}
+	defer gzReader.Close()
+
+	gzWriter, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+	gzWriter.Comment = revision
+	if _, err := io.Copy(gzWriter, gzReader); err != nil {
+		return err
+	}
+	return gzWriter.Close()
+}
+
+// copyZip copies the entries of the zip archive, with the revision as the comment.
+func (s *gitSync) copyZip(ctx context.Context, w io.Writer, entry treeEntry, revision string) error {
+	blob := &bytes.Buffer{}
+	if err := s.git(ctx, blob, "cat-file", "blob", entry.oid); err != nil {
+		return err
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(blob.Bytes()), int64(blob.Len()))
+	if err != nil {
+		return err
+	}
+
+	zipWriter := zip.NewWriter(w)
+	for _, file := range zipReader.File {
+		if err := zipWriter.Copy(file); err != nil {
+			return err
+		}
+	}
+	if err := zipWriter.SetComment(revision); err != nil {
+		return err
+	}
+	return zipWriter.Close()
+}
+
+// git runs the git command against the bare clone, writing the output to the writer if any.
+func (s *gitSync) git(ctx context.Context, stdout io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"--git-dir", s.gitDir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout = stdout
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// templateFile returns the file name of the template archive of the entry, or empty if the entry
+// is not a template.
+func templateFile(category string, entry treeEntry) string {
+	file := entry.name
+	switch entry.typ {
+	case "tree":
+		file = entry.name + ".tar.gz"
+	case "blob":
+	default:
+		return ""
+	}
+	if !templateFileRegex.MatchString(path.Join(category, file)) {
+		return ""
+	}
+	return file
+}
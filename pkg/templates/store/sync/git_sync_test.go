@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/udmire/observability-operator/pkg/templates/template"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func copyDir(t *testing.T, src, dst string) {
+	t.Helper()
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), content, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitSync_Synchronize(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	curDir, _ := os.Getwd()
+	testdata := filepath.Join(curDir, "..", "..", "template")
+
+	remote := t.TempDir()
+	runGit(t, remote, "init", "--bare", "--quiet")
+	work := t.TempDir()
+	runGit(t, work, "init", "--quiet")
+	copyDir(t, testdata, filepath.Join(work, "templates", "apps"))
+	os.WriteFile(filepath.Join(work, "templates", "apps", "README.md"), []byte("templates"), 0644)
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "--quiet", "-m", "add templates")
+	runGit(t, work, "tag", "release")
+	runGit(t, work, "push", "--quiet", remote, "HEAD:refs/heads/main", "release")
+	first := runGit(t, work, "rev-parse", "HEAD")
+
+	store := t.TempDir()
+	cfg := GitConfig{Repository: remote, Reference: "main", Paths: []string{"apps:templates/apps"}, Timeout: time.Minute}
+	s := NewGitSynchronizer(cfg, []string{"apps", "capsules"}, store, prometheus.NewRegistry(), log.NewNopLogger())
+	if err := s.Synchronize(context.Background()); err != nil {
+		t.Fatalf("Synchronize() error = %v", err)
+	}
+	if s.Revision() != first {
+		t.Errorf("Revision() = %s, want %s", s.Revision(), first)
+	}
+
+	loader := template.NewTemplateLoader(log.NewNopLogger())
+	for _, file := range []string{"app_v1.0.0.tar.gz", "app_v1.0.1.tar.gz", "app_v1.0.2.tar.gz", "app_v1.0.0.tgz"} {
+		app, err := loader.LoadTemplate(filepath.Join(store, "apps", file))
+		if err != nil || app == nil {
+			t.Fatalf("LoadTemplate(%s) = %v, %v", file, app, err)
+		}
+		if app.Revision != first {
+			t.Errorf("LoadTemplate(%s).Revision = %s, want %s", file, app.Revision, first)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(store, "apps", "README.md")); !os.IsNotExist(err) {
+		t.Errorf("unexpected file synced, err = %v", err)
+	}
+
+	runGit(t, work, "rm", "--quiet", "-r", "templates/apps/app_v1.0.0")
+	runGit(t, work, "commit", "--quiet", "-m", "remove template")
+	runGit(t, work, "push", "--quiet", remote, "HEAD:refs/heads/main")
+	second := runGit(t, work, "rev-parse", "HEAD")
+
+	if err := s.Synchronize(context.Background()); err != nil {
+		t.Fatalf("Synchronize() error = %v", err)
+	}
+	if s.Revision() != second {
+		t.Errorf("Revision() = %s, want %s", s.Revision(), second)
+	}
+	if _, err := os.Stat(filepath.Join(store, "apps", "app_v1.0.0.tar.gz")); !os.IsNotExist(err) {
+		t.Errorf("removed template not deleted, err = %v", err)
+	}
+	// the unchanged templates are not rebuilt, and keep the revision they were built from.
+	if app, _ := loader.LoadTemplate(filepath.Join(store, "apps", "app_v1.0.1.tar.gz")); app == nil || app.Revision != first {
+		t.Errorf("unchanged template rebuilt, got %v", app)
+	}
+
+	cfg.Reference = "release"
+	tagged := NewGitSynchronizer(cfg, []string{"apps"}, t.TempDir(), prometheus.NewRegistry(), log.NewNopLogger())
+	if err := tagged.Synchronize(context.Background()); err != nil {
+		t.Fatalf("Synchronize() error = %v", err)
+	}
+	if tagged.Revision() != first {
+		t.Errorf("Revision() = %s, want the tagged %s", tagged.Revision(), first)
+	}
+}
@@ -0,0 +1,236 @@
+package sync
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/crypto/tls"
+	"github.com/grafana/dskit/flagext"
+)
+
+// HTTPClientConfig configures the client fetching the templates from the remote address.
+type HTTPClientConfig struct {
+	Username        string         `yaml:"basic_auth_username"`
+	Password        flagext.Secret `yaml:"basic_auth_password"`
+	PasswordFile    string         `yaml:"basic_auth_password_file"`
+	PasswordEnv     string         `yaml:"basic_auth_password_env"`
+	BearerToken     flagext.Secret `yaml:"bearer_token"`
+	BearerTokenFile string         `yaml:"bearer_token_file"`
+	BearerTokenEnv  string         `yaml:"bearer_token_env"`
+
+	ProxyURL      string         `yaml:"proxy_url"`
+	ProxyUsername string         `yaml:"proxy_username"`
+	ProxyPassword flagext.Secret `yaml:"proxy_password"`
+
+	Timeout time.Duration    `yaml:"timeout"`
+	TLS     tls.ClientConfig `yaml:",inline"`
+	Backoff backoff.Config   `yaml:"backoff_config"`
+}
+
+func (c *HTTPClientConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&c.Username, prefix+".basic-auth-username", "", "Username of the basic auth against the remote address.")
+	f.Var(&c.Password, prefix+".basic-auth-password", "Password of the basic auth against the remote address.")
+	f.StringVar(&c.PasswordFile, prefix+".basic-auth-password-file", "", "File holding the password of the basic auth, read on every request. Takes precedence over the password and its environment variable.")
+	f.StringVar(&c.PasswordEnv, prefix+".basic-auth-password-env", "", "Environment variable holding the password of the basic auth. Takes precedence over the password.")
+	f.Var(&c.BearerToken, prefix+".bearer-token", "Bearer token sent to the remote address.")
+	f.StringVar(&c.BearerTokenFile, prefix+".bearer-token-file", "", "File holding the bearer token, read on every request. Takes precedence over the bearer token and its environment variable.")
+	f.StringVar(&c.BearerTokenEnv, prefix+".bearer-token-env", "", "Environment variable holding the bearer token. Takes precedence over the bearer token.")
+	f.StringVar(&c.ProxyURL, prefix+".proxy-url", "", "URL of the proxy to the remote address. If empty, the proxy is read from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.")
+	f.StringVar(&c.ProxyUsername, prefix+".proxy-username", "", "Username to authenticate against the proxy.")
+	f.Var(&c.ProxyPassword, prefix+".proxy-password", "Password to authenticate against the proxy.")
+	f.DurationVar(&c.Timeout, prefix+".timeout", 30*time.Second, "Timeout of each request to the remote address.")
+	c.TLS.RegisterFlagsWithPrefix(prefix, f)
+	c.Backoff.RegisterFlagsWithPrefix(prefix, f)
+}
+
+// validators are the validators of a response, sent back to skip downloading an unchanged content.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// httpClient fetches the remote contents, with authentication, retries and conditional requests.
+// The credentials are only sent to the origin of the remote address.
+type httpClient struct {
+	cfg    HTTPClientConfig
+	client *http.Client
+	origin string
+
+	mutex      sync.Mutex
+	validators map[string]validators // url & validators of the content fetched last
+}
+
+func newHTTPClient(cfg HTTPClientConfig, address string) (*httpClient, error) {
+	addressURL, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote address: %w", err)
+	}
+
+	tlsConfig, err := cfg.TLS.GetTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if len(cfg.ProxyURL) > 0 {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		if len(cfg.ProxyUsername) > 0 {
+			proxyURL.User = url.UserPassword(cfg.ProxyUsername, cfg.ProxyPassword.String())
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = tlsConfig
+
+	return &httpClient{
+		cfg:        cfg,
+		client:     &http.Client{Transport: transport, Timeout: cfg.Timeout},
+		origin:     origin(addressURL),
+		validators: make(map[string]validators),
+	}, nil
+}
+
+// Get fetches the url, retrying on the network errors and the server errors. The response is
+// 304 Not Modified if conditional and the content is unchanged since fetched last.
+func (c *httpClient) Get(ctx context.Context, url string, conditional bool) (*http.Response, error) {
+	retries := backoff.New(ctx, c.cfg.Backoff)
+	var lastErr error
+	for {
+		resp, err := c.do(ctx, url, conditional)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("unexpected status fetching %s: %s", url, resp.Status)
+		}
+		lastErr = err
+
+		retries.Wait()
+		if !retries.Ongoing() {
+			return nil, fmt.Errorf("%w (%v)", lastErr, retries.Err())
+		}
+	}
+}
+
+func (c *httpClient) do(ctx context.Context, url string, conditional bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.authorize(req); err != nil {
+		return nil, err
+	}
+	if conditional {
+		c.mutex.Lock()
+		cached := c.validators[url]
+		c.mutex.Unlock()
+		if len(cached.etag) > 0 {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if len(cached.lastModified) > 0 {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+	return c.client.Do(req)
+}
+
+// authorize sets the credentials of the request to the origin of the remote address, the requests
+// to other hosts, e.g. the archives of the index hosted elsewhere, are sent without credentials.
+func (c *httpClient) authorize(req *http.Request) error {
+	if origin(req.URL) != c.origin {
+		return nil
+	}
+
+	token, err := secret(c.cfg.BearerToken, c.cfg.BearerTokenFile, c.cfg.BearerTokenEnv)
+	if err != nil {
+		return err
+	}
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+
+	if len(c.cfg.Username) > 0 {
+		password, err := secret(c.cfg.Password, c.cfg.PasswordFile, c.cfg.PasswordEnv)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(c.cfg.Username, password)
+	}
+	return nil
+}
+
+// secret returns the secret read from the file if any, else from the environment variable if any,
+// else the value.
+func secret(value flagext.Secret, file, env string) (string, error) {
+	if len(file) > 0 {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(content)), nil
+	}
+	if len(env) > 0 {
+		return os.Getenv(env), nil
+	}
+	return value.String(), nil
+}
+
+// origin returns the scheme, host and port of the url, the port defaulting to the one of the scheme.
+func origin(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	port := u.Port()
+	if len(port) == 0 {
+		switch scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(strings.ToLower(u.Hostname()), port))
+}
+
+// Conditional returns whether the content of the url can be fetched conditionally.
+func (c *httpClient) Conditional(url string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	_, ok := c.validators[url]
+	return ok
+}
+
+// Remember records the validators of the response once its content is processed, so that the
+// content is only fetched again when changed.
+func (c *httpClient) Remember(url string, resp *http.Response) {
+	cached := validators{etag: resp.Header.Get("ETag"), lastModified: resp.Header.Get("Last-Modified")}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(cached.etag) == 0 && len(cached.lastModified) == 0 {
+		delete(c.validators, url)
+		return
+	}
+	c.validators[url] = cached
+}
+
+// Forget drops the validators of the url.
+func (c *httpClient) Forget(url string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.validators, url)
+}
@@ -1,8 +1,8 @@
 package sync
 
 import (
-	"bufio"
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +14,8 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/grafana/dskit/services"
 )
@@ -21,79 +23,149 @@ import (
 type httpSync struct {
 	*services.BasicService
 
-	cfg    Config
-	logger log.Logger
+	cfg      Config
+	logger   log.Logger
+	client   *httpClient
+	verifier *verifier
 
 	storePath string
-	templates map[string]string // template urn & path
+	index     map[string]indexEntry // the index fetched last
+	templates map[string]indexEntry // template urn & entry
 	mutex     sync.Mutex
+
+	verificationFailures *prometheus.CounterVec
+	invalidEntries       prometheus.Gauge
 }
 
-func NewHttpSynchronizer(cfg Config, storePath string, logger log.Logger) *httpSync {
+func NewHttpSynchronizer(cfg Config, storePath string, reg prometheus.Registerer, logger log.Logger) (*httpSync, error) {
+	client, err := newHTTPClient(cfg.HTTP, cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := newVerifier(cfg.Verify)
+	if err != nil {
+		return nil, err
+	}
+
 	sync := &httpSync{
 		cfg:       cfg,
 		logger:    logger,
-		templates: make(map[string]string),
+		client:    client,
+		verifier:  verifier,
+		templates: make(map[string]indexEntry),
 		storePath: storePath,
 		mutex:     sync.Mutex{},
+
+		verificationFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "observperator",
+			Name:      "templates_sync_verification_failures_total",
+			Help:      "Total number of the synced templates rejected by the checksum or signature verification.",
+		}, []string{"reason"}),
+		invalidEntries: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: "observperator",
+			Name:      "templates_sync_index_invalid_entries",
+			Help:      "The number of malformed entries skipped in the templates index fetched last.",
+		}),
 	}
 	sync.BasicService = services.NewTimerService(cfg.Interval, sync.Synchronize, sync.Synchronize, nil)
-	return sync
+	return sync, nil
 }
 
 func (s *httpSync) Synchronize(ctx context.Context) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	idx, err := s.getIndex()
+	idx, err := s.getIndex(ctx)
 	if err != nil {
 		level.Warn(s.logger).Log("msg", "failed to download templates index", "err", err)
 		return err
 	}
 
 	toAdd, toDel := compareMaps(s.templates, idx)
-	for name, url := range toAdd {
-		err := s.DownloadTemplate(name, url)
+	for name, entry := range idx {
+		_, changed := toAdd[name]
+		// the unchanged templates are only checked if the server supports conditional requests.
+		if !changed && !s.client.Conditional(entry.url) {
+			continue
+		}
+
+		err := s.DownloadTemplate(ctx, name, entry, !changed)
+		if reason := verificationReason(err); len(reason) > 0 {
+			level.Error(s.logger).Log("msg", "rejected unverified template", "template", name, "url", entry.url, "err", err)
+			s.verificationFailures.WithLabelValues(reason).Inc()
+			continue
+		}
 		if err != nil {
-			level.Warn(s.logger).Log("msg", "failed to download template", "template", name, "url", url, "err", err)
+			level.Warn(s.logger).Log("msg", "failed to download template", "template", name, "url", entry.url, "err", err)
 			continue
 		}
-		s.templates[name] = url
+		if changed && entry.deprecated {
+			level.Warn(s.logger).Log("msg", "synced deprecated template", "template", name)
+		}
+		s.templates[name] = entry
 	}
 
-	for name := range toDel {
+	for name, entry := range toDel {
 		s.RemoveTemplate(name)
+		s.client.Forget(entry.url)
 		delete(s.templates, name)
 	}
 	return nil
 }
 
-func (s *httpSync) DownloadTemplate(name, url string) error {
+// DownloadTemplate downloads the template into the store, the template is only moved into place
+// once its checksum and signature are verified. A conditional download is skipped if the template
+// is unchanged since downloaded last.
+func (s *httpSync) DownloadTemplate(ctx context.Context, name string, entry indexEntry, conditional bool) error {
 	splits := strings.SplitN(name, string(filepath.Separator), 2)
 	if len(splits) != 2 {
 		return fmt.Errorf("invalid name for templates")
 	}
+	url := entry.url
 
-	resp, err := http.Get(url)
+	resp, err := s.client.Get(ctx, url, conditional)
 	if err != nil {
 		level.Warn(s.logger).Log("msg", "cannot download template.", "url", url, "err", err)
 		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status downloading template: %s", resp.Status)
+	}
 
 	tempFile, err := os.CreateTemp(s.storePath, fmt.Sprintf("%s.temp", splits[1]))
 	if err != nil {
 		level.Warn(s.logger).Log("msg", "cannot create temp file for downloading template.", "url", url, "err", err)
 		return err
 	}
+	defer os.Remove(tempFile.Name())
 	defer tempFile.Close()
 
-	_, err = io.Copy(tempFile, resp.Body)
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tempFile, hash), resp.Body)
 	if err != nil {
 		level.Warn(s.logger).Log("msg", "cannot download template content.", "url", url, "err", err)
 		return err
 	}
 	resp.Body.Close()
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	if entry.size > 0 && entry.size != size {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrSizeMismatch, entry.size, size)
+	}
+	if err := s.verifier.VerifyChecksum(entry.checksum, hash.Sum(nil)); err != nil {
+		return err
+	}
+	if s.verifier.SignatureRequired() {
+		if err := s.verifySignature(ctx, tempFile.Name(), url); err != nil {
+			return err
+		}
+	}
 
 	err = os.MkdirAll(filepath.Join(s.storePath, splits[0]), 0755)
 	if err != nil {
@@ -107,9 +179,36 @@ func (s *httpSync) DownloadTemplate(name, url string) error {
 		return err
 	}
 
+	s.client.Remember(url, resp)
 	return nil
 }
 
+// verifySignature verifies the downloaded template against its detached signature.
+func (s *httpSync) verifySignature(ctx context.Context, file, url string) error {
+	resp, err := s.client.Get(ctx, url+s.cfg.Verify.SignatureSuffix, false)
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "cannot download template signature.", "url", url, "err", err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrSignatureMissing
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status downloading template signature: %s", resp.Status)
+	}
+	signature, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return s.verifier.VerifySignature(content, signature)
+}
+
 func (s *httpSync) RemoveTemplate(name string) {
 	splits := strings.SplitN(name, string(filepath.Separator), 2)
 	if len(splits) != 2 {
@@ -122,36 +221,48 @@ func (s *httpSync) RemoveTemplate(name string) {
 	}
 }
 
-func (s *httpSync) getIndex() (result map[string]string, err error) {
-	client := http.Client{}
+// getIndex downloads the index, the malformed entries of which are skipped and reported. The index
+// fetched last is returned if unchanged.
+func (s *httpSync) getIndex(ctx context.Context) (result map[string]indexEntry, err error) {
 	idxUri, err := url.JoinPath(s.cfg.Address, s.cfg.IndexFile)
 	if err != nil {
 		level.Warn(s.logger).Log("msg", "invalid address for templates synchorize.", "err", err)
 		return nil, err
 	}
-	resp, err := client.Get(idxUri)
+	resp, err := s.client.Get(ctx, idxUri, s.index != nil)
 	if err != nil {
 		level.Warn(s.logger).Log("msg", "cannot download templates index.", "err", err)
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return s.index, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status downloading templates index: %s", resp.Status)
+	}
 
-	result = map[string]string{}
-	scanner := bufio.NewScanner(resp.Body)
-	for scanner.Scan() {
-		line := scanner.Text()
-		normalized := normalizeFilePattern(line)
-		if normalized == "" {
-			level.Error(s.logger).Log("msg", "invalid content of templates index.", "err", err)
-			return nil, fmt.Errorf("invalid content of templates index file, provide: '%s', need match: '%s'", line, TemplateFilePattern)
-		}
-		remote, _ := url.JoinPath(s.cfg.Address, normalized)
-		result[normalized] = remote
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "cannot download templates index.", "err", err)
+		return nil, err
+	}
+	result, invalid, err := parseIndex(content, s.cfg.Address)
+	if err != nil {
+		level.Error(s.logger).Log("msg", "invalid content of templates index.", "err", err)
+		return nil, err
+	}
+	for _, err := range invalid {
+		level.Error(s.logger).Log("msg", "skipped invalid entry of templates index.", "err", err)
 	}
+	s.invalidEntries.Set(float64(len(invalid)))
+
+	s.index = result
+	s.client.Remember(idxUri, resp)
 	return result, nil
 }
 
-func compareMaps(ori, oth map[string]string) (toAdd, toDel map[string]string) {
+func compareMaps[V comparable](ori, oth map[string]V) (toAdd, toDel map[string]V) {
 	if len(ori) == 0 {
 		return oth, toDel
 	}
@@ -160,8 +271,8 @@ func compareMaps(ori, oth map[string]string) (toAdd, toDel map[string]string) {
 		return toAdd, ori
 	}
 
-	toDel = map[string]string{}
-	toAdd = map[string]string{}
+	toDel = map[string]V{}
+	toAdd = map[string]V{}
 
 	for k, v := range oth {
 		nv, exists := ori[k]
@@ -1,8 +1,29 @@
 package sync
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func Test_compareMaps(t *testing.T) {
@@ -38,3 +59,230 @@ func Test_compareMaps(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpSync_Verify(t *testing.T) {
+	curDir, _ := os.Getwd()
+	archive, err := os.ReadFile(filepath.Join(curDir, "..", "..", "template", "app_v1.0.1.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, private, _ := ed25519.GenerateKey(nil)
+	der, _ := x509.MarshalPKIXPublicKey(public)
+	keyFile := filepath.Join(t.TempDir(), "templates.pub")
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644)
+
+	sum := sha256.Sum256(archive)
+	checksum := fmt.Sprintf("sha256:%x", sum)
+	tampered := append([]byte{}, archive...)
+	tampered[len(tampered)-1] ^= 0xff
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, archive)))
+
+	files := map[string][]byte{
+		"apps/app_v1.0.1.tar.gz":     archive,
+		"apps/app_v1.0.1.tar.gz.sig": signature,
+		"apps/app_v1.0.2.tar.gz":     tampered,
+		"apps/app_v1.0.2.tar.gz.sig": signature,
+		"apps/app_v1.0.3.tar.gz":     archive,
+		"apps/app_v1.0.4.tar.gz":     archive,
+		"apps/app_v1.0.4.tar.gz.sig": signature,
+	}
+	files["index.list"] = []byte(strings.Join([]string{
+		"apps/app_v1.0.1.tar.gz " + checksum,
+		"apps/app_v1.0.2.tar.gz " + checksum,
+		"apps/app_v1.0.3.tar.gz " + checksum,
+		"apps/app_v1.0.4.tar.gz",
+	}, "\n"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		content, ok := files[strings.TrimPrefix(req.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		verify VerifyConfig
+		want   []string
+	}{
+		{
+			name:   "checksum",
+			verify: VerifyConfig{},
+			want:   []string{"app_v1.0.1.tar.gz", "app_v1.0.3.tar.gz", "app_v1.0.4.tar.gz"},
+		},
+		{
+			name:   "require checksum",
+			verify: VerifyConfig{RequireChecksum: true},
+			want:   []string{"app_v1.0.1.tar.gz", "app_v1.0.3.tar.gz"},
+		},
+		{
+			name:   "signature",
+			verify: VerifyConfig{PublicKeys: []string{keyFile}, SignatureSuffix: ".sig"},
+			want:   []string{"app_v1.0.1.tar.gz", "app_v1.0.4.tar.gz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := t.TempDir()
+			cfg := Config{Address: server.URL, IndexFile: "index.list", Verify: tt.verify}
+			s, err := NewHttpSynchronizer(cfg, store, prometheus.NewRegistry(), log.NewNopLogger())
+			if err != nil {
+				t.Fatalf("NewHttpSynchronizer() error = %v", err)
+			}
+			if err := s.Synchronize(context.Background()); err != nil {
+				t.Fatalf("Synchronize() error = %v", err)
+			}
+
+			entries, _ := os.ReadDir(filepath.Join(store, "apps"))
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("synced = %v, want %v", got, tt.want)
+			}
+			if leftovers, _ := filepath.Glob(filepath.Join(store, "*.temp*")); len(leftovers) > 0 {
+				t.Errorf("rejected templates left behind: %v", leftovers)
+			}
+		})
+	}
+}
+
+func TestHttpSync_Client(t *testing.T) {
+	curDir, _ := os.Getwd()
+	archive, err := os.ReadFile(filepath.Join(curDir, "..", "..", "template", "app_v1.0.1.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	downloads := map[string]int{}
+	failures := 1
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if req.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		content := archive
+		if req.URL.Path == "/index.list" {
+			content = []byte("apps/app_v1.0.1.tar.gz\n")
+		}
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256(content))
+		if req.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads[req.URL.Path]++
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	// the proxy tunnels the requests to the server once authenticated.
+	var proxied int
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("proxy:secret")) {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		mutex.Lock()
+		proxied++
+		mutex.Unlock()
+		upstream, err := net.Dial("tcp", req.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		go func() {
+			_, _ = io.Copy(conn, upstream)
+			conn.Close()
+		}()
+	}))
+	defer proxy.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	_ = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644)
+	tokenFile := filepath.Join(dir, "token")
+	_ = os.WriteFile(tokenFile, []byte("token\n"), 0600)
+
+	cfg := Config{Address: server.URL, IndexFile: "index.list"}
+	cfg.HTTP.BearerTokenFile = tokenFile
+	cfg.HTTP.TLS.CAPath = caFile
+	cfg.HTTP.ProxyURL = proxy.URL
+	cfg.HTTP.ProxyUsername = "proxy"
+	_ = cfg.HTTP.ProxyPassword.Set("secret")
+	cfg.HTTP.Timeout = time.Second
+	cfg.HTTP.Backoff = backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 3}
+
+	store := t.TempDir()
+	s, err := NewHttpSynchronizer(cfg, store, prometheus.NewRegistry(), log.NewNopLogger())
+	if err != nil {
+		t.Fatalf("NewHttpSynchronizer() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.Synchronize(context.Background()); err != nil {
+			t.Fatalf("Synchronize() error = %v", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(store, "apps", "app_v1.0.1.tar.gz")); err != nil {
+		t.Errorf("template not synced, err = %v", err)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if want := map[string]int{"/index.list": 1, "/apps/app_v1.0.1.tar.gz": 1}; !reflect.DeepEqual(downloads, want) {
+		t.Errorf("downloads = %v, want %v", downloads, want)
+	}
+	if proxied == 0 {
+		t.Errorf("requests not sent through the proxy")
+	}
+}
+
+func Test_httpClient_authorize(t *testing.T) {
+	t.Setenv("TEMPLATES_SYNC_TOKEN", "env-token")
+	tests := []struct {
+		name string
+		cfg  HTTPClientConfig
+		url  string
+		want string
+	}{
+		{name: "same origin", cfg: HTTPClientConfig{BearerToken: flagext.SecretWithValue("token")}, url: "https://templates.example.com/apps/app_v1.0.0.zip", want: "Bearer token"},
+		{name: "default port", cfg: HTTPClientConfig{BearerToken: flagext.SecretWithValue("token")}, url: "https://templates.example.com:443/index.list", want: "Bearer token"},
+		{name: "other host", cfg: HTTPClientConfig{BearerToken: flagext.SecretWithValue("token")}, url: "https://mirror.example.com/apps/app_v1.0.0.zip"},
+		{name: "other scheme", cfg: HTTPClientConfig{BearerToken: flagext.SecretWithValue("token")}, url: "http://templates.example.com/apps/app_v1.0.0.zip"},
+		{name: "token env", cfg: HTTPClientConfig{BearerToken: flagext.SecretWithValue("token"), BearerTokenEnv: "TEMPLATES_SYNC_TOKEN"}, url: "https://templates.example.com/index.list", want: "Bearer env-token"},
+		{name: "password env", cfg: HTTPClientConfig{Username: "user", PasswordEnv: "TEMPLATES_SYNC_TOKEN"}, url: "https://templates.example.com/index.list", want: "Basic " + base64.StdEncoding.EncodeToString([]byte("user:env-token"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newHTTPClient(tt.cfg, "https://templates.example.com/templates")
+			if err != nil {
+				t.Fatalf("newHTTPClient() error = %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if err := c.authorize(req); err != nil {
+				t.Fatalf("authorize() error = %v", err)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("authorize() Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
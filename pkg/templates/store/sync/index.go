@@ -0,0 +1,168 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// IndexAPIVersion is the version of the structured index format.
+	IndexAPIVersion = "v1"
+)
+
+var categoryRegex = regexp.MustCompile("^[a-zA-Z]+$")
+
+// Index is the structured index of a templates repository, either in JSON or YAML.
+type Index struct {
+	APIVersion string       `json:"apiVersion"`
+	Generated  time.Time    `json:"generated,omitempty"`
+	Entries    []IndexEntry `json:"entries"`
+}
+
+// IndexEntry is a version of a template in the structured index.
+type IndexEntry struct {
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	// URL of the template archive, either absolute or relative to the address of the repository.
+	URL string `json:"url"`
+	// Digest of the template archive, as `sha256:<hex>`.
+	Digest      string    `json:"digest,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	Created     time.Time `json:"created,omitempty"`
+	Deprecated  bool      `json:"deprecated,omitempty"`
+	Description string    `json:"description,omitempty"`
+}
+
+// indexEntry is a template listed in the index, with its checksum if any.
+type indexEntry struct {
+	url        string
+	checksum   string
+	size       int64
+	deprecated bool
+}
+
+// parseIndex parses the index, either structured or a list of `<category>/<name>_v<version>.<ext>`
+// lines optionally followed by the `sha256:<hex>` checksum. The malformed entries are skipped and
+// returned as the errors of the entries, the index is only rejected as a whole if unsupported.
+func parseIndex(content []byte, address string) (map[string]indexEntry, []error, error) {
+	index := &Index{}
+	if err := yaml.Unmarshal(content, index); err == nil && len(index.APIVersion) > 0 {
+		if index.APIVersion != IndexAPIVersion {
+			return nil, nil, fmt.Errorf("unsupported templates index version %s", index.APIVersion)
+		}
+		result, invalid := parseStructuredIndex(index, address)
+		return result, invalid, nil
+	}
+	return parseListIndex(content, address)
+}
+
+func parseStructuredIndex(index *Index, address string) (map[string]indexEntry, []error) {
+	base, _ := url.Parse(strings.TrimSuffix(address, "/") + "/")
+
+	result := map[string]indexEntry{}
+	seen := map[string]bool{}
+	var invalid []error
+	for i, item := range index.Entries {
+		file, template, entry, err := parseIndexEntry(item, base)
+		if err == nil && seen[template] {
+			err = fmt.Errorf("duplicated template %s", template)
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Errorf("entry %d: %w", i, err))
+			continue
+		}
+		seen[template] = true
+		result[file] = entry
+	}
+	return result, invalid
+}
+
+// parseIndexEntry returns the file the template is stored as, the `<category>/<name>_v<version>`
+// of the template, and the entry of the template.
+func parseIndexEntry(item IndexEntry, base *url.URL) (file, template string, entry indexEntry, err error) {
+	entry = indexEntry{size: item.Size, deprecated: item.Deprecated}
+	if !categoryRegex.MatchString(item.Category) {
+		return "", "", entry, fmt.Errorf("invalid category '%s'", item.Category)
+	}
+	if len(item.Name) == 0 || len(item.Version) == 0 {
+		return "", "", entry, fmt.Errorf("missing name or version")
+	}
+	if len(item.Digest) > 0 && !checksumRegex.MatchString(item.Digest) {
+		return "", "", entry, fmt.Errorf("invalid digest '%s', need match: '%s'", item.Digest, checksumRegex)
+	}
+	if item.Size < 0 {
+		return "", "", entry, fmt.Errorf("invalid size %d", item.Size)
+	}
+	entry.checksum = strings.ToLower(item.Digest)
+
+	location, err := url.Parse(item.URL)
+	if err != nil || len(item.URL) == 0 {
+		return "", "", entry, fmt.Errorf("invalid url '%s'", item.URL)
+	}
+	if base != nil {
+		location = base.ResolveReference(location)
+	}
+	entry.url = location.String()
+
+	ext := ""
+	for _, candidate := range []string{".zip", ".tgz", ".tar.gz"} {
+		if strings.HasSuffix(strings.ToLower(location.Path), candidate) {
+			ext = candidate
+		}
+	}
+	version := item.Version
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	template = path.Join(item.Category, fmt.Sprintf("%s_%s", item.Name, version))
+	file = template + ext
+	if !templateFileRegex.MatchString(file) {
+		return "", "", entry, fmt.Errorf("template %s need match: '%s'", file, TemplateFilePattern)
+	}
+	return file, template, entry, nil
+}
+
+func parseListIndex(content []byte, address string) (map[string]indexEntry, []error, error) {
+	result := map[string]indexEntry{}
+	var invalid []error
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		normalized := ""
+		if len(fields) <= 2 {
+			normalized = normalizeFilePattern(fields[0])
+		}
+		if normalized == "" {
+			invalid = append(invalid, fmt.Errorf("invalid line '%s', need match: '%s'", line, TemplateFilePattern))
+			continue
+		}
+		entry := indexEntry{}
+		if len(fields) == 2 {
+			if !checksumRegex.MatchString(fields[1]) {
+				invalid = append(invalid, fmt.Errorf("invalid checksum of template '%s', need match: '%s'", normalized, checksumRegex))
+				continue
+			}
+			entry.checksum = strings.ToLower(fields[1])
+		}
+		entry.url, _ = url.JoinPath(address, normalized)
+		result[normalized] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return result, invalid, nil
+}
@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"reflect"
+	"testing"
+)
+
+const checksum = "sha256:4f6e2b3a5c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f"
+
+func Test_parseIndex(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		want        map[string]indexEntry
+		wantInvalid int
+		wantErr     bool
+	}{
+		{
+			name: "list",
+			content: "apps/app_v1.0.0.tar.gz\n" +
+				"./capsules/app_v1.0.1.zip " + checksum + "\n" +
+				"\n" +
+				"apps/app-latest.tar.gz\n" +
+				"apps/app_v1.0.2.tgz sha256:bad\n" +
+				"x/../../../tmp/apps/node_v1.zip\n",
+			want: map[string]indexEntry{
+				"apps/app_v1.0.0.tar.gz":  {url: "http://templates/repo/apps/app_v1.0.0.tar.gz"},
+				"capsules/app_v1.0.1.zip": {url: "http://templates/repo/capsules/app_v1.0.1.zip", checksum: checksum},
+			},
+			wantInvalid: 3,
+		},
+		{
+			name: "yaml",
+			content: `apiVersion: v1
+generated: "2023-06-01T00:00:00Z"
+entries:
+- category: apps
+  name: node-exporter
+  version: 1.5.0
+  url: apps/node-exporter-1.5.0.tar.gz
+  digest: ` + checksum + `
+  size: 1024
+  created: "2023-06-01T00:00:00Z"
+  description: exports the metrics of the nodes.
+- category: apps
+  name: node-exporter
+  version: v1.4.0
+  url: https://mirror/node-exporter-1.4.0.zip
+  deprecated: true
+- category: apps
+  name: node-exporter
+  version: 1.6.0
+  url: apps/node-exporter-1.6.0.exe
+- category: apps
+  version: 1.7.0
+  url: apps/node-exporter-1.7.0.tgz
+- category: apps
+  name: node-exporter
+  version: 1.5.0
+  url: apps/node-exporter-1.5.0-copy.tgz
+`,
+			want: map[string]indexEntry{
+				"apps/node-exporter_v1.5.0.tar.gz": {url: "http://templates/repo/apps/node-exporter-1.5.0.tar.gz", checksum: checksum, size: 1024},
+				"apps/node-exporter_v1.4.0.zip":    {url: "https://mirror/node-exporter-1.4.0.zip", deprecated: true},
+			},
+			wantInvalid: 3,
+		},
+		{
+			name:    "json",
+			content: `{"apiVersion": "v1", "entries": [{"category": "capsules", "name": "app", "version": "v2.0.0", "url": "/archives/app.tgz"}]}`,
+			want: map[string]indexEntry{
+				"capsules/app_v2.0.0.tgz": {url: "http://templates/archives/app.tgz"},
+			},
+		},
+		{
+			name:    "unsupported version",
+			content: `{"apiVersion": "v2", "entries": []}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, invalid, err := parseIndex([]byte(tt.content), "http://templates/repo")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIndex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseIndex() = %v, want %v", got, tt.want)
+			}
+			if len(invalid) != tt.wantInvalid {
+				t.Errorf("parseIndex() invalid = %v, want %d", invalid, tt.wantInvalid)
+			}
+		})
+	}
+}
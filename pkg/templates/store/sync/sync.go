@@ -2,7 +2,6 @@ package sync
 
 import (
 	"flag"
-	"regexp"
 	"strings"
 	"time"
 )
@@ -18,19 +17,33 @@ type Config struct {
 	Address   string        `yaml:"address"`
 	IndexFile string        `yaml:"index"`
 	Interval  time.Duration `yaml:"interval"`
+
+	HTTP   HTTPClientConfig `yaml:"http"`
+	Verify VerifyConfig     `yaml:"verify"`
+	Git    GitConfig        `yaml:"git"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&c.Enabled, "templates.store.sync.enabled", false, "Weather syncing templates from remote or not.")
 	f.DurationVar(&c.Interval, "templates.store.sync.interval", 10*time.Minute, "Interval of syncing templates from remote")
 	f.StringVar(&c.Address, "templates.store.sync.address", "", "Remote address of the templates.")
-	f.StringVar(&c.IndexFile, "templates.store.sync.index", "index.list", "List of templates at the remote address. Should be each oneline")
+	f.StringVar(&c.IndexFile, "templates.store.sync.index", "index.list", "Index of the templates at the remote address, either a structured JSON or YAML index, or a list of the template paths one per line.")
+
+	c.HTTP.RegisterFlagsWithPrefix("templates.store.sync.http", f)
+	c.Verify.RegisterFlags(f)
+	c.Git.RegisterFlags(f)
 }
 
+// normalizeFilePattern returns the `<type>/<name>_<version>.<ext>` path of the template file, or
+// empty if the content is not exactly the path of a template file, e.g. escaping the store.
 func normalizeFilePattern(content string) string {
-	reg := regexp.MustCompile(TemplateFilePattern)
-	if reg.MatchString(content) {
-		return strings.TrimLeft(content, "./")
+	for _, segment := range strings.Split(content, "/") {
+		if segment == ".." {
+			return ""
+		}
+	}
+	if !templateFileRegex.MatchString(content) {
+		return ""
 	}
-	return ""
+	return strings.TrimPrefix(content, "./")
 }
@@ -18,6 +18,27 @@ func Test_normalizeFilePattern(t *testing.T) {
 			},
 			want: "abc/app_v1.0.0.zip",
 		},
+		{
+			name: "traversal",
+			args: args{
+				content: "x/../../../tmp/apps/node_v1.zip",
+			},
+			want: "",
+		},
+		{
+			name: "nested",
+			args: args{
+				content: "mirror/apps/node_v1.zip",
+			},
+			want: "",
+		},
+		{
+			name: "suffix",
+			args: args{
+				content: "apps/node_v1.zip.exe",
+			},
+			want: "",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,151 @@
+package sync
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/dskit/flagext"
+)
+
+const (
+	// ChecksumPrefix prefixes the sha256 checksum of a template in the index.
+	ChecksumPrefix = "sha256:"
+)
+
+var checksumRegex = regexp.MustCompile("^" + ChecksumPrefix + "[0-9a-fA-F]{64}$")
+
+var (
+	ErrChecksumMissing  = errors.New("template checksum missing in index")
+	ErrChecksumMismatch = errors.New("template checksum mismatch")
+	ErrSizeMismatch     = errors.New("template size mismatch")
+	ErrSignatureMissing = errors.New("template signature missing")
+	ErrSignatureInvalid = errors.New("template signature invalid")
+)
+
+// VerifyConfig configures the verification of the synced template archives.
+type VerifyConfig struct {
+	RequireChecksum bool                   `yaml:"require_checksum"`
+	PublicKeys      flagext.StringSliceCSV `yaml:"public_keys"`
+	SignatureSuffix string                 `yaml:"signature_suffix"`
+}
+
+func (c *VerifyConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.RequireChecksum, "templates.store.sync.verify.require-checksum", false, "Reject the templates without a sha256 checksum in the index.")
+	f.Var(&c.PublicKeys, "templates.store.sync.verify.public-keys", "Comma-separated list of PEM files of the ed25519 or ECDSA public keys the templates are signed with. If set, the templates without a valid signature are rejected.")
+	f.StringVar(&c.SignatureSuffix, "templates.store.sync.verify.signature-suffix", ".sig", "Suffix of the detached signature of a template, downloaded next to the template.")
+}
+
+// verifier verifies the checksums and the detached signatures of the template archives. The
+// signatures are either raw or base64 encoded, as produced by `cosign sign-blob` with a key pair.
+type verifier struct {
+	requireChecksum bool
+	keys            []crypto.PublicKey
+}
+
+func newVerifier(cfg VerifyConfig) (*verifier, error) {
+	v := &verifier{requireChecksum: cfg.RequireChecksum}
+	for _, file := range cfg.PublicKeys {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parsePublicKey(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %w", file, err)
+		}
+		v.keys = append(v.keys, key)
+	}
+	return v, nil
+}
+
+func parsePublicKey(content []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// SignatureRequired returns whether the templates must be signed.
+func (v *verifier) SignatureRequired() bool {
+	return len(v.keys) > 0
+}
+
+// VerifyChecksum verifies the sha256 sum of the template against the checksum in the index.
+func (v *verifier) VerifyChecksum(checksum string, sum []byte) error {
+	if len(checksum) == 0 {
+		if v.requireChecksum {
+			return ErrChecksumMissing
+		}
+		return nil
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(checksum, ChecksumPrefix))
+	if err != nil || !bytes.Equal(expected, sum) {
+		return fmt.Errorf("%w: expected %s, got %s%x", ErrChecksumMismatch, checksum, ChecksumPrefix, sum)
+	}
+	return nil
+}
+
+// VerifySignature verifies the detached signature of the template against any of the public keys.
+func (v *verifier) VerifySignature(content, signature []byte) error {
+	if len(signature) == 0 {
+		return ErrSignatureMissing
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+
+	digest := sha256.Sum256(content)
+	for _, key := range v.keys {
+		switch k := key.(type) {
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, content, signature) {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest[:], signature) {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureInvalid
+}
+
+// verificationReason returns the reason of the verification failure reported in the metrics.
+func verificationReason(err error) string {
+	switch {
+	case errors.Is(err, ErrChecksumMissing):
+		return "checksum_missing"
+	case errors.Is(err, ErrChecksumMismatch):
+		return "checksum_mismatch"
+	case errors.Is(err, ErrSizeMismatch):
+		return "size_mismatch"
+	case errors.Is(err, ErrSignatureMissing):
+		return "signature_missing"
+	case errors.Is(err, ErrSignatureInvalid):
+		return "signature_invalid"
+	}
+	return ""
+}
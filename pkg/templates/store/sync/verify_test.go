@@ -0,0 +1,54 @@
+package sync
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func Test_verifier_VerifySignature(t *testing.T) {
+	content := []byte("template")
+	digest := sha256.Sum256(content)
+
+	edPublic, edPrivate, _ := ed25519.GenerateKey(nil)
+	ecPrivate, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecSignature, _ := ecdsa.SignASN1(rand.Reader, ecPrivate, digest[:])
+	_, otherPrivate, _ := ed25519.GenerateKey(nil)
+
+	v := &verifier{}
+	for _, public := range []interface{}{edPublic, &ecPrivate.PublicKey} {
+		der, _ := x509.MarshalPKIXPublicKey(public)
+		key, err := parsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		if err != nil {
+			t.Fatalf("parsePublicKey() error = %v", err)
+		}
+		v.keys = append(v.keys, key)
+	}
+
+	tests := []struct {
+		name      string
+		content   []byte
+		signature []byte
+		wantErr   error
+	}{
+		{name: "ed25519 raw", content: content, signature: ed25519.Sign(edPrivate, content)},
+		{name: "cosign ecdsa base64", content: content, signature: []byte(base64.StdEncoding.EncodeToString(ecSignature) + "\n")},
+		{name: "tampered", content: []byte("tampered"), signature: ed25519.Sign(edPrivate, content), wantErr: ErrSignatureInvalid},
+		{name: "unknown key", content: content, signature: ed25519.Sign(otherPrivate, content), wantErr: ErrSignatureInvalid},
+		{name: "unsigned", content: content, wantErr: ErrSignatureMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := v.VerifySignature(tt.content, tt.signature); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifySignature() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
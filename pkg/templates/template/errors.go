@@ -0,0 +1,14 @@
+package template
+
+import "errors"
+
+var (
+	// ErrTemplateNotFound is returned when no template matches the requested name and version.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrInvalidTemplate is returned when a template file cannot be decoded into a manifest.
+	ErrInvalidTemplate = errors.New("invalid template")
+	// ErrInvalidValues is returned when the values of an instance are rejected by the template.
+	ErrInvalidValues = errors.New("invalid values")
+	// ErrUnsafeArchive is returned when a template archive escapes its folder or exceeds the limits.
+	ErrUnsafeArchive = errors.New("unsafe template archive")
+)
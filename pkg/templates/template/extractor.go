@@ -0,0 +1,135 @@
+package template
+
+import (
+	"archive/zip"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxFileSize  = 4 << 20
+	defaultMaxTotalSize = 64 << 20
+	defaultMaxFiles     = 1000
+)
+
+// LoaderConfig limits the content extracted from the template archives, which is held in memory
+// while the template is loaded. The zero values are unlimited.
+type LoaderConfig struct {
+	MaxFileSize  int64 `yaml:"max_file_size"`
+	MaxTotalSize int64 `yaml:"max_total_size"`
+	MaxFiles     int   `yaml:"max_files"`
+}
+
+func (c *LoaderConfig) RegisterFlags(f *flag.FlagSet) {
+	f.Int64Var(&c.MaxFileSize, "templates.loader.max-file-size", defaultMaxFileSize, "Maximum uncompressed size in bytes of a file in a template archive, 0 to disable.")
+	f.Int64Var(&c.MaxTotalSize, "templates.loader.max-total-size", defaultMaxTotalSize, "Maximum uncompressed size in bytes of all the files in a template archive, 0 to disable.")
+	f.IntVar(&c.MaxFiles, "templates.loader.max-files", defaultMaxFiles, "Maximum number of entries in a template archive, 0 to disable.")
+}
+
+// DefaultLoaderConfig returns the default limits of the template archives.
+func DefaultLoaderConfig() LoaderConfig {
+	return LoaderConfig{
+		MaxFileSize:  defaultMaxFileSize,
+		MaxTotalSize: defaultMaxTotalSize,
+		MaxFiles:     defaultMaxFiles,
+	}
+}
+
+// extractor extracts the entries of an archive into the in-memory file system, rejecting the
+// entries escaping the archive and the archives exceeding the limits.
+type extractor struct {
+	cfg  LoaderConfig
+	fsys *memFS
+
+	files int
+	total int64
+}
+
+func (l *templatesLoader) newExtractor() *extractor {
+	return &extractor{cfg: l.cfg, fsys: newMemFS()}
+}
+
+// FS returns the file system of the extracted entries.
+func (e *extractor) FS() fs.FS {
+	return e.fsys
+}
+
+// unsafeEntry returns the error rejecting the entry of the archive.
+func unsafeEntry(name, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: entry %q: %s", ErrUnsafeArchive, name, fmt.Sprintf(format, args...))
+}
+
+// target returns the clean path of the entry in the file system, counting the entries of the archive.
+func (e *extractor) target(name string) (string, error) {
+	e.files++
+	if e.cfg.MaxFiles > 0 && e.files > e.cfg.MaxFiles {
+		return "", unsafeEntry(name, "more than %d entries", e.cfg.MaxFiles)
+	}
+
+	clean := strings.TrimSuffix(name, "/")
+	if strings.Contains(clean, "\\") || !filepath.IsLocal(filepath.FromSlash(clean)) {
+		return "", unsafeEntry(name, "path outside of the archive")
+	}
+	return path.Clean(clean), nil
+}
+
+// Dir creates the directory entry.
+func (e *extractor) Dir(name string) error {
+	target, err := e.target(name)
+	if err != nil {
+		return err
+	}
+	_, err = e.fsys.mkdirAll(target, 0755)
+	return err
+}
+
+// ZipFile extracts the regular file of the zip archive.
+func (e *extractor) ZipFile(file *zip.File) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("entry %q: %w", file.Name, err)
+	}
+	defer reader.Close()
+	return e.File(file.Name, file.Mode(), file.Modified, reader)
+}
+
+// File extracts the regular file, the content read from the reader.
+func (e *extractor) File(name string, mode os.FileMode, modTime time.Time, reader io.Reader) error {
+	target, err := e.target(name)
+	if err != nil {
+		return err
+	}
+
+	// read one more byte than the limits allow, to tell the oversize files.
+	limit := int64(-1)
+	if e.cfg.MaxFileSize > 0 {
+		limit = e.cfg.MaxFileSize
+	}
+	if e.cfg.MaxTotalSize > 0 && (limit < 0 || e.cfg.MaxTotalSize-e.total < limit) {
+		limit = e.cfg.MaxTotalSize - e.total
+	}
+	if limit >= 0 {
+		reader = io.LimitReader(reader, limit+1)
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("entry %q: %w", name, err)
+	}
+	written := int64(len(content))
+	e.total += written
+	if e.cfg.MaxFileSize > 0 && written > e.cfg.MaxFileSize {
+		return unsafeEntry(name, "larger than %d bytes", e.cfg.MaxFileSize)
+	}
+	if e.cfg.MaxTotalSize > 0 && e.total > e.cfg.MaxTotalSize {
+		return unsafeEntry(name, "archive larger than %d bytes in total", e.cfg.MaxTotalSize)
+	}
+	return e.fsys.add(target, content, mode.Perm()|0600, modTime)
+}
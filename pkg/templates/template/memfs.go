@@ -0,0 +1,191 @@
+package template
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// memFS is a read-only in-memory file system holding the entries extracted from an archive, so
+// that the templates are loaded without writing the archives to the disk.
+type memFS struct {
+	root *memEntry
+}
+
+var (
+	_ fs.ReadDirFS  = (*memFS)(nil)
+	_ fs.ReadFileFS = (*memFS)(nil)
+)
+
+func newMemFS() *memFS {
+	return &memFS{root: newMemDir(".", fs.ModePerm)}
+}
+
+// memEntry is either a file or a directory of the memFS, as well as its fs.FileInfo and fs.DirEntry.
+type memEntry struct {
+	name     string
+	data     []byte
+	mode     fs.FileMode
+	modTime  time.Time
+	children map[string]*memEntry
+}
+
+func newMemDir(name string, perm fs.FileMode) *memEntry {
+	return &memEntry{name: name, mode: fs.ModeDir | perm, children: map[string]*memEntry{}}
+}
+
+func (e *memEntry) Name() string               { return e.name }
+func (e *memEntry) Size() int64                { return int64(len(e.data)) }
+func (e *memEntry) Mode() fs.FileMode          { return e.mode }
+func (e *memEntry) ModTime() time.Time         { return e.modTime }
+func (e *memEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *memEntry) Sys() interface{}           { return nil }
+func (e *memEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *memEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+// entries returns the children of the directory sorted by name.
+func (e *memEntry) entries() []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(e.children))
+	for _, child := range e.children {
+		entries = append(entries, child)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries
+}
+
+// lookup returns the entry of the slash separated path, the op naming the errors.
+func (m *memFS) lookup(op, name string) (*memEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	entry := m.root
+	if name == "." {
+		return entry, nil
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if !entry.IsDir() {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		child, ok := entry.children[elem]
+		if !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		entry = child
+	}
+	return entry, nil
+}
+
+// mkdirAll creates the directory along with its missing parents.
+func (m *memFS) mkdirAll(name string, perm fs.FileMode) (*memEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entry := m.root
+	if name == "." {
+		return entry, nil
+	}
+	for _, elem := range strings.Split(name, "/") {
+		child, ok := entry.children[elem]
+		if !ok {
+			child = newMemDir(elem, perm)
+			entry.children[elem] = child
+		} else if !child.IsDir() {
+			return nil, &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+		}
+		entry = child
+	}
+	return entry, nil
+}
+
+// add creates the file with the content, replacing the file of the same path if any.
+func (m *memFS) add(name string, data []byte, perm fs.FileMode, modTime time.Time) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+	parent, err := m.mkdirAll(path.Dir(name), fs.ModePerm)
+	if err != nil {
+		return err
+	}
+	base := path.Base(name)
+	if existing, ok := parent.children[base]; ok && existing.IsDir() {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	}
+	parent.children[base] = &memEntry{name: base, data: data, mode: perm.Perm(), modTime: modTime}
+	return nil
+}
+
+func (m *memFS) Open(name string) (fs.File, error) {
+	entry, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir() {
+		return &memDir{entry: entry, children: entry.entries()}, nil
+	}
+	return &memFile{entry: entry, Reader: bytes.NewReader(entry.data)}, nil
+}
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return entry.entries(), nil
+}
+
+func (m *memFS) ReadFile(name string) ([]byte, error) {
+	entry, err := m.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	return bytes.Clone(entry.data), nil
+}
+
+// memFile is an opened file of the memFS.
+type memFile struct {
+	*bytes.Reader
+	entry *memEntry
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is an opened directory of the memFS.
+type memDir struct {
+	entry    *memEntry
+	children []fs.DirEntry
+	offset   int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: fs.ErrInvalid}
+}
+
+func (d *memDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := d.children[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.children)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	d.offset += count
+	return remaining[:count], nil
+}
@@ -0,0 +1,36 @@
+package template
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func Test_memFS(t *testing.T) {
+	fsys := newMemFS()
+	if _, err := fsys.mkdirAll("app/empty", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"app/template.yaml":          "description: app",
+		"app/app_configmap.yaml":     "kind: ConfigMap",
+		"app/comp/comp_secret.yaml":  "kind: Secret",
+		"app/comp/comp_service.yaml": "",
+	} {
+		if err := fsys.add(name, []byte(content), 0644, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fstest.TestFS(fsys, "app/template.yaml", "app/app_configmap.yaml", "app/comp/comp_secret.yaml", "app/comp/comp_service.yaml", "app/empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fsys.add("app/comp", nil, 0644, time.Now()); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("add() over a directory error = %v, want %v", err, fs.ErrExist)
+	}
+	if _, err := fsys.mkdirAll("app/template.yaml/dir", 0755); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("mkdirAll() under a file error = %v, want %v", err, fs.ErrExist)
+	}
+}
@@ -0,0 +1,103 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// MetadataFile is the optional descriptor at the root of a template archive.
+const MetadataFile = "template.yaml"
+
+// Metadata describes the template, it is declared in the metadata file of the archive.
+type Metadata struct {
+	Description string       `json:"description,omitempty"`
+	Maintainers []Maintainer `json:"maintainers,omitempty"`
+	// Parameters are the values the template files are rendered against.
+	Parameters []Parameter `json:"parameters,omitempty"`
+	// OperatorVersion is the range of the operator versions supporting the template, e.g. ">=0.2 <1".
+	OperatorVersion string `json:"operatorVersion,omitempty"`
+	// Capsules are the capsule templates required by the template.
+	Capsules []Requirement `json:"capsules,omitempty"`
+	// Components describe the workloads of the template by their names.
+	Components map[string]Component `json:"components,omitempty"`
+
+	// Extends is the base template the template is an overlay of, as `<name>@<version>`. The
+	// version is either a version or a constraint, the latest version if omitted.
+	Extends string `json:"extends,omitempty"`
+	// RemoveComponents are the components of the base template left out of the overlay.
+	RemoveComponents []string `json:"removeComponents,omitempty"`
+}
+
+type Maintainer struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+type Parameter struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+}
+
+// Requirement references a template by name and, optionally, version.
+type Requirement struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type Component struct {
+	Description string `json:"description,omitempty"`
+}
+
+// Defaults returns the default values of the parameters.
+func (m *Metadata) Defaults() map[string]interface{} {
+	defaults := make(map[string]interface{})
+	if m == nil {
+		return defaults
+	}
+	for _, param := range m.Parameters {
+		if param.Default != nil {
+			defaults[param.Name] = param.Default
+		}
+	}
+	return defaults
+}
+
+// Base returns the name and the version of the template the template extends, false if the
+// template extends no template.
+func (m *Metadata) Base() (name, version string, ok bool) {
+	if m == nil || len(m.Extends) == 0 {
+		return "", "", false
+	}
+	name, version, _ = strings.Cut(m.Extends, "@")
+	return name, version, true
+}
+
+func parseMetadata(content []byte) (*Metadata, error) {
+	metadata := &Metadata{}
+	if err := yaml.UnmarshalStrict(content, metadata); err != nil {
+		return nil, fmt.Errorf("%w: cannot decode %s: %v", ErrInvalidTemplate, MetadataFile, err)
+	}
+
+	for i, param := range metadata.Parameters {
+		if len(param.Name) == 0 {
+			return nil, fmt.Errorf("%w: %s: parameter %d has no name", ErrInvalidTemplate, MetadataFile, i)
+		}
+	}
+	for i, capsule := range metadata.Capsules {
+		if len(capsule.Name) == 0 {
+			return nil, fmt.Errorf("%w: %s: capsule %d has no name", ErrInvalidTemplate, MetadataFile, i)
+		}
+	}
+	if name, _, ok := metadata.Base(); ok && len(name) == 0 {
+		return nil, fmt.Errorf("%w: %s: extends %q has no name", ErrInvalidTemplate, MetadataFile, metadata.Extends)
+	}
+	if len(metadata.RemoveComponents) > 0 && len(metadata.Extends) == 0 {
+		return nil, fmt.Errorf("%w: %s: removes components without extending a template", ErrInvalidTemplate, MetadataFile)
+	}
+	return metadata, nil
+}
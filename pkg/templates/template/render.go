@@ -0,0 +1,35 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	gotemplate "text/template"
+)
+
+// RenderedFileSuffix marks the template files rendered with Go templates, such as
+// "app_deployment.yaml.tpl". The other files are taken as they are, because the configurations
+// shipped in them (alerting templates, dashboards) often use the same delimiters.
+const RenderedFileSuffix = ".tpl"
+
+// Render renders the content of the file against the values, files without the rendered file
+// suffix are returned unchanged.
+func (f *TemplateFile) Render(values *Values) ([]byte, error) {
+	if !strings.HasSuffix(f.FileName, RenderedFileSuffix) {
+		return f.Content, nil
+	}
+	if values == nil {
+		values = &Values{}
+	}
+
+	tmpl, err := gotemplate.New(f.FileName).Funcs(funcMap()).Parse(string(f.Content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot parse %s: %v", ErrInvalidTemplate, f.FileName, err)
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, values); err != nil {
+		return nil, fmt.Errorf("%w: cannot render %s: %v", ErrInvalidTemplate, f.FileName, err)
+	}
+	return buf.Bytes(), nil
+}
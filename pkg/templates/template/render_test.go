@@ -0,0 +1,62 @@
+package template
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTemplateFile_Render(t *testing.T) {
+	values := &Values{
+		Values: map[string]interface{}{
+			"port": 9100,
+			"args": []interface{}{"--web.listen-address=:9100", "--collector.systemd"},
+		},
+		Instance: InstanceInfo{Name: "node-exporter", Namespace: "monitoring"},
+		Template: TemplateInfo{Name: "node-exporter", Version: "1.5.0"},
+		Cluster:  ClusterInfo{Name: "dev"},
+	}
+
+	tests := []struct {
+		name    string
+		file    *TemplateFile
+		want    string
+		wantErr error
+	}{
+		{
+			name: "static file",
+			file: &TemplateFile{FileName: "app_configmap.yaml", Content: []byte("template: '{{ .Labels.job }}'")},
+			want: "template: '{{ .Labels.job }}'",
+		},
+		{
+			name: "values",
+			file: &TemplateFile{FileName: "app_service.yaml.tpl", Content: []byte("name: {{ .Instance.Name }}-{{ .Cluster.Name }}\nport: {{ .Values.port }}")},
+			want: "name: node-exporter-dev\nport: 9100",
+		},
+		{
+			name: "helpers",
+			file: &TemplateFile{FileName: "app_deployment.yaml.tpl", Content: []byte("args:{{ toYaml .Values.args | nindent 2 }}\nscrape: {{ .Values.scrape | default \"30s\" | quote }}")},
+			want: "args:\n  - --web.listen-address=:9100\n  - --collector.systemd\nscrape: \"30s\"",
+		},
+		{
+			name:    "required",
+			file:    &TemplateFile{FileName: "app_secret.yaml.tpl", Content: []byte("token: {{ required \"token is required\" .Values.token }}")},
+			wantErr: ErrInvalidTemplate,
+		},
+		{
+			name:    "invalid syntax",
+			file:    &TemplateFile{FileName: "app_secret.yaml.tpl", Content: []byte("token: {{ .Values.token ")},
+			wantErr: ErrInvalidTemplate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.file.Render(values)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// SchemaFile is the optional JSON schema of the values at the root of a template archive.
+const SchemaFile = "values.schema.json"
+
+// ValuesSchema validates the values the template files are rendered against.
+type ValuesSchema struct {
+	schema *jsonschema.Schema
+}
+
+func parseSchema(content []byte) (*ValuesSchema, error) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(SchemaFile, bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("%w: cannot decode %s: %v", ErrInvalidTemplate, SchemaFile, err)
+	}
+	schema, err := compiler.Compile(SchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot compile %s: %v", ErrInvalidTemplate, SchemaFile, err)
+	}
+	return &ValuesSchema{schema: schema}, nil
+}
+
+// Validate validates the values against the schema, the errors are reported under the path
+// of the values. A nil schema accepts all the values.
+func (s *ValuesSchema) Validate(values map[string]interface{}, fldPath *field.Path) field.ErrorList {
+	if s == nil {
+		return nil
+	}
+
+	err := s.schema.Validate(values)
+	if err == nil {
+		return nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+
+	var errs field.ErrorList
+	for _, cause := range leafCauses(validationErr) {
+		path := instancePath(fldPath, cause.InstanceLocation)
+		errs = append(errs, field.Invalid(path, lookup(values, cause.InstanceLocation), cause.Message))
+	}
+	// Keep the messages stable, they are written into the status.
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+	return errs
+}
+
+// leafCauses returns the innermost causes of the error, which point at the invalid values.
+func leafCauses(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var causes []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		causes = append(causes, leafCauses(cause)...)
+	}
+	return causes
+}
+
+// instancePath converts the JSON pointer of the invalid value into a field path.
+func instancePath(fldPath *field.Path, pointer string) *field.Path {
+	for _, token := range pointerTokens(pointer) {
+		if index, err := strconv.Atoi(token); err == nil {
+			fldPath = fldPath.Index(index)
+		} else {
+			fldPath = fldPath.Child(token)
+		}
+	}
+	return fldPath
+}
+
+// lookup returns the value the JSON pointer points at, nil if it does not exist.
+func lookup(values map[string]interface{}, pointer string) interface{} {
+	var current interface{} = values
+	for _, token := range pointerTokens(pointer) {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[token]
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil
+			}
+			current = node[index]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func pointerTokens(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if len(pointer) == 0 {
+		return nil
+	}
+	tokens := strings.Split(pointer, "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
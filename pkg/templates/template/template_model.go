@@ -14,6 +14,14 @@ type TemplateFile struct {
 type AppTemplate struct {
 	TemplateBase
 	Workloads map[string]*WorkloadTemplate
+
+	// Metadata is declared in the metadata file of the template, nil if absent.
+	Metadata *Metadata
+	// Schema validates the values of the template, nil if the template ships no schema.
+	Schema *ValuesSchema
+	// Revision is the source revision of the template, e.g. the git commit it is built from,
+	// recorded in the comment of the archive.
+	Revision string
 }
 
 type WorkloadTemplate struct {
@@ -3,13 +3,16 @@ package template
 import (
 	"archive/tar"
 	"archive/zip"
+	"bytes"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -24,15 +27,26 @@ const (
 
 type TemplateLoader interface {
 	LoadTemplate(path string) (*AppTemplate, error)
+	// LoadFolder loads the template `<name>_<version>` from the folder holding its files.
+	LoadFolder(appVer, dir string) (*AppTemplate, error)
+	// LoadFiles loads the template `<name>_<version>` from the contents of its files by their slash
+	// separated paths, extracted in memory within the limits of the archives.
+	LoadFiles(appVer string, files map[string][]byte) (*AppTemplate, error)
 	TemplateName(path string) (string, string)
 }
 
 type templatesLoader struct {
+	cfg    LoaderConfig
 	logger log.Logger
 }
 
+// NewTemplateLoader creates the loader limiting the archives with the default limits.
 func NewTemplateLoader(logger log.Logger) TemplateLoader {
-	tl := &templatesLoader{logger: logger}
+	return NewTemplateLoaderWithConfig(DefaultLoaderConfig(), logger)
+}
+
+func NewTemplateLoaderWithConfig(cfg LoaderConfig, logger log.Logger) TemplateLoader {
+	tl := &templatesLoader{cfg: cfg, logger: logger}
 	return tl
 }
 
@@ -54,6 +68,36 @@ func (l *templatesLoader) LoadTemplate(path string) (app *AppTemplate, err error
 	return app, err
 }
 
+func (l *templatesLoader) LoadFolder(appVer, dir string) (*AppTemplate, error) {
+	app, err := l.loadTemplateWithFolder(appVer, dir)
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "load to template failed", "path", dir, "err", err)
+	}
+	return app, err
+}
+
+func (l *templatesLoader) LoadFiles(appVer string, files map[string][]byte) (*AppTemplate, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	extractor := l.newExtractor()
+	for _, name := range names {
+		if err := extractor.File(name, 0644, time.Time{}, bytes.NewReader(files[name])); err != nil {
+			level.Warn(l.logger).Log("msg", "cannot extract template files", "template", appVer, "err", err)
+			return nil, err
+		}
+	}
+
+	app, err := l.loadTemplateFS(appVer, extractor.FS())
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "load to template failed", "template", appVer, "err", err)
+	}
+	return app, err
+}
+
 func (l *templatesLoader) handleZipFile(path, appVer string) (*AppTemplate, error) {
 	zipFile, err := zip.OpenReader(path)
 	if err != nil {
@@ -62,41 +106,24 @@ func (l *templatesLoader) handleZipFile(path, appVer string) (*AppTemplate, erro
 	}
 	defer zipFile.Close()
 
-	tempDir, err := os.MkdirTemp("", "template-")
-	if err != nil {
-		level.Warn(l.logger).Log("msg", "cannot create temp space for unzip template", "path", path, "err", err)
-		return nil, err
-	}
-	defer os.RemoveAll(tempDir)
-
+	extractor := l.newExtractor()
 	for _, file := range zipFile.File {
-		destPath := filepath.Join(tempDir, file.Name)
-		if file.FileInfo().IsDir() {
-			os.MkdirAll(destPath, file.Mode())
-			continue
-		}
-		fileReader, err := file.Open()
-		if err != nil {
-			level.Warn(l.logger).Log("msg", "cannot open zipfile reader", "file", file.Name, "err", err)
-			return nil, err
-		}
-		defer fileReader.Close()
-
-		destFile, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
-		if err != nil {
-			level.Warn(l.logger).Log("msg", "cannot create temp file for content", "err", err)
-			return nil, err
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			err = extractor.Dir(file.Name)
+		case mode.IsRegular():
+			err = extractor.ZipFile(file)
+		default:
+			err = unsafeEntry(file.Name, "unsupported %s entry", mode.Type())
 		}
-		defer destFile.Close()
-
-		_, err = io.Copy(destFile, fileReader)
 		if err != nil {
-			level.Warn(l.logger).Log("msg", "cannot copy content to temp file", "err", err)
+			level.Warn(l.logger).Log("msg", "cannot extract zip template", "path", path, "err", err)
 			return nil, err
 		}
 	}
 
-	return l.loadTemplateWithFolder(appVer, tempDir)
+	return l.loadTemplateWithRevision(appVer, extractor.FS(), zipFile.Comment)
 }
 
 func (l *templatesLoader) handleTarGzFile(path, appVer string) (*AppTemplate, error) {
@@ -116,13 +143,7 @@ func (l *templatesLoader) handleTarGzFile(path, appVer string) (*AppTemplate, er
 
 	tarReader := tar.NewReader(gzReader)
 
-	tempDir, err := os.MkdirTemp("", "template-")
-	if err != nil {
-		level.Warn(l.logger).Log("msg", "cannot create temp space for tar.gz template", "path", path, "err", err)
-		return nil, err
-	}
-	defer os.RemoveAll(tempDir)
-
+	extractor := l.newExtractor()
 	for {
 		header, err := tarReader.Next()
 		if err == io.EOF {
@@ -133,53 +154,61 @@ func (l *templatesLoader) handleTarGzFile(path, appVer string) (*AppTemplate, er
 			return nil, err
 		}
 
-		target := filepath.Join(tempDir, header.Name)
-
 		switch header.Typeflag {
 		case tar.TypeDir:
-			err = os.MkdirAll(target, os.FileMode(header.Mode))
-			if err != nil {
-				level.Warn(l.logger).Log("msg", "cannot create directory in temp space", "target", target, "err", err)
-				return nil, err
-			}
-		case tar.TypeReg:
-			file, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
-				level.Warn(l.logger).Log("msg", "cannot create file in temp space ", "target", target, "err", err)
-				return nil, err
-			}
-			defer file.Close()
-
-			_, err = io.Copy(file, tarReader)
-			if err != nil {
-				level.Warn(l.logger).Log("msg", "cannot copy content in temp space", "target", target, "err", err)
-				return nil, err
-			}
+			err = extractor.Dir(header.Name)
+		case tar.TypeReg, tar.TypeRegA:
+			err = extractor.File(header.Name, os.FileMode(header.Mode), header.ModTime, tarReader)
+		case tar.TypeXGlobalHeader:
+			// the global header written by git archive, which holds no content.
+		case tar.TypeSymlink, tar.TypeLink:
+			err = unsafeEntry(header.Name, "link to %s", header.Linkname)
+		default:
+			err = unsafeEntry(header.Name, "unsupported entry type %q", header.Typeflag)
 		}
+		if err != nil {
+			level.Warn(l.logger).Log("msg", "cannot extract tar.gz template", "path", path, "err", err)
+			return nil, err
+		}
+	}
+
+	return l.loadTemplateWithRevision(appVer, extractor.FS(), gzReader.Comment)
+}
+
+// loadTemplateWithRevision loads the template extracted from the archive, the comment of the
+// archive holds the source revision if any.
+func (l *templatesLoader) loadTemplateWithRevision(appVer string, appFS fs.FS, comment string) (*AppTemplate, error) {
+	app, err := l.loadTemplateFS(appVer, appFS)
+	if err != nil {
+		return nil, err
 	}
+	app.Revision = strings.TrimSpace(comment)
+	return app, nil
+}
 
-	return l.loadTemplateWithFolder(appVer, tempDir)
+func (l *templatesLoader) loadTemplateWithFolder(appVer, dir string) (*AppTemplate, error) {
+	return l.loadTemplateFS(appVer, os.DirFS(dir))
 }
 
-func (l *templatesLoader) loadTemplateWithFolder(appVer, tempDir string) (app *AppTemplate, err error) {
+// loadTemplateFS loads the template from the files of the file system, either at its root or
+// in the only `<name>_<version>` or `<name>` folder of the root.
+func (l *templatesLoader) loadTemplateFS(appVer string, appFS fs.FS) (app *AppTemplate, err error) {
 	appVerArr := strings.Split(appVer, "_")
 	if len(appVerArr) != 2 {
 		level.Warn(l.logger).Log("msg", "the template filename not match pattern 'app_version'", "template", appVer)
 		return nil, fmt.Errorf("invalid app package name %s", appVer)
 	}
 
-	appFS := os.DirFS(tempDir)
-
 	rootPath := "."
 	var exists bool
-	if exists, err = utils.HasOnlySubDirectory(tempDir, appVer); exists {
+	if exists, err = utils.HasOnlySubDirectory(appFS, appVer); exists {
 		rootPath = appVer
 	}
 	if err != nil {
 		level.Warn(l.logger).Log("msg", "inliad application package", "err", err)
 		return nil, err
 	}
-	if exists, err = utils.HasOnlySubDirectory(tempDir, appVerArr[0]); exists {
+	if exists, err = utils.HasOnlySubDirectory(appFS, appVerArr[0]); exists {
 		rootPath = appVerArr[0]
 	}
 	if err != nil {
@@ -197,6 +226,14 @@ func (l *templatesLoader) loadTemplateWithFolder(appVer, tempDir string) (app *A
 			return err
 		}
 
+		// skip the hidden entries, e.g. the version control and the editor files.
+		if path != rootPath && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
 		if entry.IsDir() {
 			if path == appVer || path == rootPath {
 				return nil
@@ -218,10 +255,18 @@ func (l *templatesLoader) loadTemplateWithFolder(appVer, tempDir string) (app *A
 
 			base := filepath.Dir(path)
 			var templateBase *TemplateBase
-			if base == appVer || base == rootPath {
+			if (base == appVer || base == rootPath) && entry.Name() == MetadataFile {
+				app.Metadata, err = parseMetadata(content)
+				return err
+			} else if (base == appVer || base == rootPath) && entry.Name() == SchemaFile {
+				app.Schema, err = parseSchema(content)
+				return err
+			} else if base == appVer || base == rootPath {
 				templateBase = &app.TemplateBase
+			} else if work, ok := app.Workloads[filepath.Base(base)]; ok && filepath.Dir(base) == rootPath {
+				templateBase = &work.TemplateBase
 			} else {
-				templateBase = &app.Workloads[base].TemplateBase
+				return fmt.Errorf("unexpected template file %s", path)
 			}
 
 			templateBase.TemplateFiles = append(templateBase.TemplateFiles, &TemplateFile{
@@ -1,6 +1,16 @@
 package template
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/go-kit/log"
@@ -145,3 +155,262 @@ func Test_templatesLoader_fileExt(t *testing.T) {
 		})
 	}
 }
+
+func Test_templatesLoader_loadTemplateWithFolder_metadata(t *testing.T) {
+	dir := t.TempDir()
+	metadata := `description: Exports the metrics of the nodes.
+maintainers:
+- name: udmire
+  email: udmire@udmire.cn
+parameters:
+- name: port
+  default: 9100
+- name: token
+  required: true
+operatorVersion: ">=0.2 <1"
+capsules:
+- name: node-exporter-dashboards
+  version: 1.0.0
+components:
+  exporter:
+    description: The exporter daemonset.
+`
+	if err := os.WriteFile(filepath.Join(dir, MetadataFile), []byte(metadata), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app_configmap.yaml"), []byte("kind: ConfigMap"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &templatesLoader{logger: log.NewNopLogger()}
+	app, err := l.loadTemplateWithFolder("node-exporter_1.5.0", dir)
+	if err != nil {
+		t.Fatalf("loadTemplateWithFolder() error = %v", err)
+	}
+	if len(app.TemplateFiles) != 1 {
+		t.Errorf("loadTemplateWithFolder() loaded %d files, want 1", len(app.TemplateFiles))
+	}
+	if app.Metadata == nil {
+		t.Fatal("loadTemplateWithFolder() metadata = nil")
+	}
+	if app.Metadata.Description != "Exports the metrics of the nodes." || app.Metadata.Components["exporter"].Description != "The exporter daemonset." {
+		t.Errorf("loadTemplateWithFolder() metadata = %+v", app.Metadata)
+	}
+	if want := map[string]interface{}{"port": float64(9100)}; !reflect.DeepEqual(app.Metadata.Defaults(), want) {
+		t.Errorf("Metadata.Defaults() = %v, want %v", app.Metadata.Defaults(), want)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, MetadataFile), []byte("parameters: [{default: 1}]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.loadTemplateWithFolder("node-exporter_1.5.0", dir); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("loadTemplateWithFolder() error = %v, want %v", err, ErrInvalidTemplate)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, MetadataFile), []byte("extends: node-exporter@v1.5.0\nremoveComponents: [exporter]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if app, err = l.loadTemplateWithFolder("blackbox-exporter_1.0.0", dir); err != nil {
+		t.Fatalf("loadTemplateWithFolder() error = %v", err)
+	}
+	if name, version, ok := app.Metadata.Base(); !ok || name != "node-exporter" || version != "v1.5.0" {
+		t.Errorf("Metadata.Base() = %s, %s, %v, want node-exporter, v1.5.0", name, version, ok)
+	}
+	if err = os.WriteFile(filepath.Join(dir, MetadataFile), []byte("extends: \"@v1.5.0\""), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.loadTemplateWithFolder("blackbox-exporter_1.0.0", dir); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("loadTemplateWithFolder() error = %v, want %v", err, ErrInvalidTemplate)
+	}
+}
+
+// tarEntry is an entry of the archives built by the tests.
+type tarEntry struct {
+	header  tar.Header
+	content string
+}
+
+func writeTarGz(t testing.TB, path string, entries ...tarEntry) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzWriter)
+	for _, entry := range entries {
+		entry.header.Size = int64(len(entry.content))
+		if entry.header.Mode == 0 {
+			entry.header.Mode = 0644
+		}
+		if err := tarWriter.WriteHeader(&entry.header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tarWriter.Close()
+	gzWriter.Close()
+}
+
+func Test_templatesLoader_unsafeArchive(t *testing.T) {
+	regular := func(name, content string) tarEntry {
+		return tarEntry{header: tar.Header{Name: name, Typeflag: tar.TypeReg}, content: content}
+	}
+	cfg := LoaderConfig{MaxFileSize: 16, MaxTotalSize: 28, MaxFiles: 3}
+
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		wantErr string
+	}{
+		{name: "safe", entries: []tarEntry{regular("app/app_configmap.yaml", "kind: ConfigMap"), regular("app/comp/comp_secret.yaml", "kind: Secret")}},
+		{name: "parent", entries: []tarEntry{regular("../escaped.yaml", "kind: ConfigMap")}, wantErr: `entry "../escaped.yaml": path outside of the archive`},
+		{name: "nested parent", entries: []tarEntry{regular("app/../../escaped.yaml", "")}, wantErr: "path outside of the archive"},
+		{name: "absolute", entries: []tarEntry{regular("/tmp/escaped.yaml", "")}, wantErr: "path outside of the archive"},
+		{name: "symlink", entries: []tarEntry{{header: tar.Header{Name: "app/link.yaml", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}}}, wantErr: `entry "app/link.yaml": link to /etc/passwd`},
+		{name: "hardlink", entries: []tarEntry{{header: tar.Header{Name: "app/link.yaml", Typeflag: tar.TypeLink, Linkname: "app/other.yaml"}}}, wantErr: "link to app/other.yaml"},
+		{name: "oversize file", entries: []tarEntry{regular("app/big.yaml", strings.Repeat("x", 17))}, wantErr: `entry "app/big.yaml": larger than 16 bytes`},
+		{name: "oversize archive", entries: []tarEntry{regular("app/a.yaml", strings.Repeat("x", 16)), regular("app/b.yaml", strings.Repeat("x", 16))}, wantErr: `entry "app/b.yaml": archive larger than 28 bytes in total`},
+		{name: "too many files", entries: []tarEntry{regular("app/a.yaml", ""), regular("app/b.yaml", ""), regular("app/c.yaml", ""), regular("app/d.yaml", "")}, wantErr: `entry "app/d.yaml": more than 3 entries`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app_v1.0.0.tar.gz")
+			writeTarGz(t, path, tt.entries...)
+
+			l := NewTemplateLoaderWithConfig(cfg, log.NewNopLogger())
+			_, err := l.LoadTemplate(path)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("LoadTemplate() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrUnsafeArchive) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadTemplate() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_templatesLoader_LoadFiles(t *testing.T) {
+	l := NewTemplateLoaderWithConfig(LoaderConfig{MaxFileSize: 16, MaxFiles: 3}, log.NewNopLogger())
+	app, err := l.LoadFiles("app_v1.0.0", map[string][]byte{
+		"app_configmap.yaml":    []byte("kind: ConfigMap"),
+		"comp/comp_secret.yaml": []byte("kind: Secret"),
+	})
+	if err != nil {
+		t.Fatalf("LoadFiles() error = %v", err)
+	}
+	if app.Name != "app" || app.Version != "v1.0.0" || len(app.TemplateFiles) != 1 || app.Workloads["comp"] == nil {
+		t.Errorf("LoadFiles() = %+v, want the template and its component", app)
+	}
+
+	tests := map[string]map[string][]byte{
+		"larger than 16 bytes":        {"app_configmap.yaml": []byte(strings.Repeat("x", 17))},
+		"more than 3 entries":         {"a.yaml": nil, "b.yaml": nil, "c.yaml": nil, "d.yaml": nil},
+		"path outside of the archive": {"../escaped.yaml": []byte("kind: ConfigMap")},
+	}
+	for wantErr, files := range tests {
+		if _, err := l.LoadFiles("app_v1.0.0", files); !errors.Is(err, ErrUnsafeArchive) || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("LoadFiles() error = %v, want %s", err, wantErr)
+		}
+	}
+}
+
+func Test_templatesLoader_unsafeZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app_v1.0.0.zip")
+	file, _ := os.Create(path)
+	zipWriter := zip.NewWriter(file)
+	header := &zip.FileHeader{Name: "app/link.yaml"}
+	header.SetMode(os.ModeSymlink | 0777)
+	writer, _ := zipWriter.CreateHeader(header)
+	_, _ = writer.Write([]byte("/etc/passwd"))
+	writer, _ = zipWriter.Create("../escaped.yaml")
+	_, _ = writer.Write([]byte("kind: ConfigMap"))
+	zipWriter.Close()
+	file.Close()
+
+	_, err := NewTemplateLoader(log.NewNopLogger()).LoadTemplate(path)
+	if !errors.Is(err, ErrUnsafeArchive) || !strings.Contains(err.Error(), `entry "app/link.yaml"`) {
+		t.Errorf("LoadTemplate() error = %v, want the symlink rejected", err)
+	}
+}
+
+// extractToDir extracts the tar.gz archive to the directory, the way the templates were loaded
+// before being extracted in memory.
+func extractToDir(b *testing.B, path, dir string) {
+	b.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer file.Close()
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+		target := filepath.Join(dir, header.Name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			b.Fatal(err)
+		}
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(out, tarReader); err != nil {
+			b.Fatal(err)
+		}
+		out.Close()
+	}
+}
+
+func BenchmarkTemplatesLoader_LoadTemplate(b *testing.B) {
+	var entries []tarEntry
+	content := strings.Repeat("key: value\n", 100)
+	entries = append(entries, tarEntry{header: tar.Header{Name: "app/app_configmap.yaml", Typeflag: tar.TypeReg}, content: content})
+	for i := 0; i < 20; i++ {
+		for _, kind := range []string{"deployment", "service", "configmap", "secret", "serviceaccount"} {
+			name := fmt.Sprintf("app/comp%d/comp%d_%s.yaml", i, i, kind)
+			entries = append(entries, tarEntry{header: tar.Header{Name: name, Typeflag: tar.TypeReg}, content: content})
+		}
+	}
+	path := filepath.Join(b.TempDir(), "app_v1.0.0.tar.gz")
+	writeTarGz(b, path, entries...)
+
+	l := &templatesLoader{cfg: DefaultLoaderConfig(), logger: log.NewNopLogger()}
+	b.Run("in-memory", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := l.LoadTemplate(path); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("temp-dir", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dir, err := os.MkdirTemp("", "template-")
+			if err != nil {
+				b.Fatal(err)
+			}
+			extractToDir(b, path, dir)
+			if _, err := l.loadTemplateWithFolder("app_v1.0.0", dir); err != nil {
+				b.Fatal(err)
+			}
+			os.RemoveAll(dir)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package template
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Values are the values the template files are rendered against.
+type Values struct {
+	// Values are the free-form values specified for the instance.
+	Values map[string]interface{}
+
+	Instance InstanceInfo
+	Template TemplateInfo
+	Cluster  ClusterInfo
+}
+
+// InstanceInfo describes the instance the template is rendered for.
+type InstanceInfo struct {
+	Name      string
+	Namespace string
+}
+
+// TemplateInfo describes the template being rendered.
+type TemplateInfo struct {
+	Name    string
+	Version string
+}
+
+// ClusterInfo describes the cluster the operator runs in.
+type ClusterInfo struct {
+	Name string
+}
+
+// MergeValues merges the values specified for an instance, in JSON, over the defaults of the
+// template parameters. The merged values are validated against the parameters and the schema
+// of the template, the errors are reported under the path of the values.
+func (t *AppTemplate) MergeValues(raw []byte, fldPath *field.Path) (map[string]interface{}, field.ErrorList) {
+	values := t.Metadata.Defaults()
+	if len(raw) > 0 {
+		specified := map[string]interface{}{}
+		if err := json.Unmarshal(raw, &specified); err != nil {
+			return nil, field.ErrorList{field.Invalid(fldPath, string(raw), err.Error())}
+		}
+		for key, value := range specified {
+			values[key] = value
+		}
+	}
+
+	var errs field.ErrorList
+	if t.Metadata != nil {
+		for _, param := range t.Metadata.Parameters {
+			if _, exists := values[param.Name]; param.Required && !exists {
+				errs = append(errs, field.Required(fldPath.Child(param.Name), "required by the template"))
+			}
+		}
+	}
+	errs = append(errs, t.Schema.Validate(values, fldPath)...)
+	return values, errs
+}
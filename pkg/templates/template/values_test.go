@@ -0,0 +1,76 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestAppTemplate_MergeValues(t *testing.T) {
+	schema, err := parseSchema([]byte(`{
+  "type": "object",
+  "properties": {
+    "port": {"type": "integer", "minimum": 1},
+    "args": {"type": "array", "items": {"type": "string"}}
+  }
+}`))
+	if err != nil {
+		t.Fatalf("parseSchema() error = %v", err)
+	}
+	appTemplate := &AppTemplate{
+		Metadata: &Metadata{Parameters: []Parameter{
+			{Name: "port", Default: float64(9100)},
+			{Name: "token", Required: true},
+		}},
+		Schema: schema,
+	}
+
+	tests := []struct {
+		name     string
+		raw      string
+		want     map[string]interface{}
+		wantErrs []string
+	}{
+		{
+			name: "defaults",
+			raw:  `{"token": "secret"}`,
+			want: map[string]interface{}{"port": float64(9100), "token": "secret"},
+		},
+		{
+			name: "overridden",
+			raw:  `{"port": 9200, "token": "secret"}`,
+			want: map[string]interface{}{"port": float64(9200), "token": "secret"},
+		},
+		{
+			name:     "missing required",
+			raw:      `{}`,
+			wantErrs: []string{"spec.values.token"},
+		},
+		{
+			name:     "rejected by schema",
+			raw:      `{"port": "9100", "args": ["--debug", 1], "token": "secret"}`,
+			wantErrs: []string{"spec.values.args[1]", "spec.values.port"},
+		},
+		{
+			name:     "not an object",
+			raw:      `[]`,
+			wantErrs: []string{"spec.values"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := appTemplate.MergeValues([]byte(tt.raw), field.NewPath("spec", "values"))
+			var gotErrs []string
+			for _, err := range errs {
+				gotErrs = append(gotErrs, err.Field)
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Fatalf("MergeValues() errs = %v, want %v", errs, tt.wantErrs)
+			}
+			if len(tt.wantErrs) == 0 && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
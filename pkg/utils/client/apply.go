@@ -0,0 +1,197 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/udmire/observability-operator/pkg/utils"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// OperationResultSkipped means that the object was not written, the object last applied
+// has the same spec hash.
+const OperationResultSkipped controllerutil.OperationResult = "skipped"
+
+// Apply applies the given object against the client with server-side apply. Only the fields
+// set in the object are owned by the operator, fields set by others (replicas managed by HPAs,
+// injected sidecars, defaulted cluster IPs) are kept. Conflicts with other managers are forced.
+//
+// The hash of the object is recorded in the spec hash annotation, the write is skipped when the live
+// object carries the same hash and was not modified by other managers since. When the update is
+// rejected because of immutable fields, the object is deleted and created again.
+func Apply(ctx context.Context, c client.Client, obj client.Object) (controllerutil.OperationResult, error) {
+	gvk, err := c.GroupVersionKindFor(obj)
+	if err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("failed to resolve the kind of %s: %w", obj.GetName(), err)
+	}
+
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	hash, err := specHash(obj)
+	if err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("failed to hash %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+
+	exist := obj.DeepCopyObject().(client.Object)
+	err = c.Get(ctx, client.ObjectKeyFromObject(obj), exist)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return controllerutil.OperationResultNone, fmt.Errorf("failed to retrieve existing %s: %w", gvk.Kind, err)
+	}
+	created := k8s_errors.IsNotFound(err)
+	if !created && exist.GetDeletionTimestamp().IsZero() && exist.GetAnnotations()[utils.SpecHashAnnotation] == hash && !modifiedSinceApplied(exist) {
+		return OperationResultSkipped, nil
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[utils.SpecHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+
+	err = c.Patch(ctx, obj, client.Apply, client.FieldOwner(utils.DefaultManagedByValue), client.ForceOwnership)
+	if !created && isImmutableFieldError(err) {
+		// Many fields are immutable after creation (selectors, statefulset volume claims,
+		// job templates), so we need to delete and recreate. We should be mindful when making
+		// changes to try and avoid this when possible.
+		if err = c.Delete(ctx, exist, client.PropagationPolicy(meta_v1.DeletePropagationBackground)); err != nil && !k8s_errors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, fmt.Errorf("failed to update %s when deleting old %s: %w", gvk.Kind, obj.GetName(), err)
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		obj.SetResourceVersion("")
+		if err = c.Patch(ctx, obj, client.Apply, client.FieldOwner(utils.DefaultManagedByValue), client.ForceOwnership); err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("failed to update %s when creating replacement %s: %w", gvk.Kind, obj.GetName(), err)
+		}
+		return controllerutil.OperationResultUpdated, nil
+	}
+	if err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("failed to apply %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+
+	switch {
+	case created:
+		return controllerutil.OperationResultCreated, nil
+	case exist.GetResourceVersion() != obj.GetResourceVersion():
+		return controllerutil.OperationResultUpdated, nil
+	default:
+		return controllerutil.OperationResultNone, nil
+	}
+}
+
+// immutableFieldMessages are the messages of the validation errors rejecting the changes of the
+// fields which cannot be changed after creation: selectors and job templates, service cluster IPs,
+// and the statefulset spec besides a few fields.
+var immutableFieldMessages = []string{
+	"field is immutable",
+	"may not change once set",
+	"updates to statefulset spec for fields other than",
+}
+
+// isImmutableFieldError reports whether the update is rejected because it changes fields
+// which cannot be changed after creation. Other invalid or forbidden updates, which would be
+// rejected again once the object is recreated, are not.
+func isImmutableFieldError(err error) bool {
+	if !k8s_errors.IsInvalid(err) {
+		return false
+	}
+	var status k8s_errors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return false
+	}
+	for _, cause := range status.Status().Details.Causes {
+		for _, message := range immutableFieldMessages {
+			if strings.Contains(cause.Message, message) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// modifiedSinceApplied reports whether other managers changed the object after it was last applied
+// by the operator. Updates of the subresources (status, scale) are not taken as modifications, nor
+// are the labels and annotations the operator does not own, e.g. the revision annotation of the
+// deployments set by the deployment controller.
+func modifiedSinceApplied(obj client.Object) bool {
+	var applied *meta_v1.ManagedFieldsEntry
+	for i, entry := range obj.GetManagedFields() {
+		if entry.Manager == utils.DefaultManagedByValue && entry.Operation == meta_v1.ManagedFieldsOperationApply {
+			applied = &obj.GetManagedFields()[i]
+		}
+	}
+	if applied == nil || applied.Time == nil {
+		return true
+	}
+	owned, err := fieldSet(applied.FieldsV1)
+	if err != nil {
+		return true
+	}
+
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == utils.DefaultManagedByValue || len(entry.Subresource) > 0 || entry.Time == nil {
+			continue
+		}
+		// The times are in seconds, the same second is taken as modified.
+		if entry.Time.Before(applied.Time) {
+			continue
+		}
+		fields, err := fieldSet(entry.FieldsV1)
+		if err != nil {
+			return true
+		}
+		modified := false
+		fields.Iterate(func(path fieldpath.Path) {
+			if !modified && (owned.Has(path) || !isMetadataField(path)) {
+				modified = true
+			}
+		})
+		if modified {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldSet returns the set of the fields of the managed fields entry.
+func fieldSet(fields *meta_v1.FieldsV1) (*fieldpath.Set, error) {
+	set := &fieldpath.Set{}
+	if fields == nil {
+		return set, nil
+	}
+	return set, set.FromJSON(bytes.NewReader(fields.Raw))
+}
+
+// isMetadataField reports whether the path is of the labels or annotations of the object.
+func isMetadataField(path fieldpath.Path) bool {
+	if len(path) < 2 || path[0].FieldName == nil || *path[0].FieldName != "metadata" || path[1].FieldName == nil {
+		return false
+	}
+	return *path[1].FieldName == "labels" || *path[1].FieldName == "annotations"
+}
+
+// specHash returns the hash of the object to be applied, the spec hash annotation excluded.
+func specHash(obj client.Object) (string, error) {
+	annotations := obj.GetAnnotations()
+	if _, exists := annotations[utils.SpecHashAnnotation]; exists {
+		delete(annotations, utils.SpecHashAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+
+	content, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
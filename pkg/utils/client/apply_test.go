@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/udmire/observability-operator/pkg/utils"
+	apps_v1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_isImmutableFieldError(t *testing.T) {
+	kind := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "immutable_selector",
+			err: k8s_errors.NewInvalid(kind, "node-exporter", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "selector"), nil, "field is immutable"),
+			}),
+			want: true,
+		},
+		{
+			name: "forbidden_statefulset_update",
+			err: k8s_errors.NewInvalid(kind, "prometheus", field.ErrorList{
+				field.Forbidden(field.NewPath("spec"), "updates to statefulset spec for fields other than 'replicas' are forbidden"),
+			}),
+			want: true,
+		},
+		{
+			name: "immutable_cluster_ip",
+			err: k8s_errors.NewInvalid(schema.GroupKind{Kind: "Service"}, "node-exporter", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "clusterIPs").Index(0), "10.0.0.2", "may not change once set"),
+			}),
+			want: true,
+		},
+		{
+			name: "forbidden_pod_spec",
+			err: k8s_errors.NewInvalid(kind, "node-exporter", field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "template", "spec", "hostNetwork"), "not allowed by the pod security policy"),
+			}),
+			want: false,
+		},
+		{
+			name: "invalid_value",
+			err: k8s_errors.NewInvalid(kind, "node-exporter", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+			}),
+			want: false,
+		},
+		{
+			name: "conflict",
+			err:  k8s_errors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "node-exporter", errors.New("conflict")),
+			want: false,
+		},
+		{
+			name: "nil",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isImmutableFieldError(tt.err); got != tt.want {
+				t.Errorf("isImmutableFieldError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply_skipped(t *testing.T) {
+	desired := func() *v1.ConfigMap {
+		return &v1.ConfigMap{
+			ObjectMeta: meta_v1.ObjectMeta{Name: "node-exporter", Namespace: "default"},
+			Data:       map[string]string{"config.yaml": "scrape_interval: 30s"},
+		}
+	}
+
+	live := desired()
+	live.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("ConfigMap"))
+	hash, err := specHash(live)
+	if err != nil {
+		t.Fatalf("specHash() error = %v", err)
+	}
+	live.Annotations = map[string]string{utils.SpecHashAnnotation: hash}
+	live.ManagedFields = []meta_v1.ManagedFieldsEntry{
+		{Manager: utils.DefaultManagedByValue, Operation: meta_v1.ManagedFieldsOperationApply, Time: &meta_v1.Time{Time: time.Now()}},
+	}
+
+	c := fake.NewClientBuilder().WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)).WithObjects(live).Build()
+	result, err := Apply(context.Background(), c, desired())
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result != OperationResultSkipped {
+		t.Errorf("Apply() = %v, want %v", result, OperationResultSkipped)
+	}
+}
+
+func Test_modifiedSinceApplied(t *testing.T) {
+	applied := meta_v1.NewTime(time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC))
+	before := meta_v1.NewTime(applied.Add(-time.Minute))
+	after := meta_v1.NewTime(applied.Add(time.Minute))
+	fields := func(raw string) *meta_v1.FieldsV1 {
+		return &meta_v1.FieldsV1{Raw: []byte(raw)}
+	}
+	apply := meta_v1.ManagedFieldsEntry{Manager: utils.DefaultManagedByValue, Operation: meta_v1.ManagedFieldsOperationApply, Time: &applied,
+		FieldsV1: fields(`{"f:metadata":{"f:annotations":{"f:observability.udmire.cn/spec-hash":{}}},"f:spec":{"f:replicas":{},"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"exporter\"}":{".":{},"f:image":{}}}}}}}`)}
+	edit := `{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"exporter\"}":{"f:image":{}}}}}}}`
+
+	tests := []struct {
+		name    string
+		entries []meta_v1.ManagedFieldsEntry
+		want    bool
+	}{
+		{
+			name: "never applied",
+			entries: []meta_v1.ManagedFieldsEntry{
+				{Manager: "kubectl-client-side-apply", Operation: meta_v1.ManagedFieldsOperationUpdate, Time: &before},
+			},
+			want: true,
+		},
+		{
+			name:    "only applied",
+			entries: []meta_v1.ManagedFieldsEntry{apply},
+			want:    false,
+		},
+		{
+			name: "updated before applied",
+			entries: []meta_v1.ManagedFieldsEntry{
+				apply,
+				{Manager: "kubectl-edit", Operation: meta_v1.ManagedFieldsOperationUpdate, Time: &before, FieldsV1: fields(edit)},
+			},
+			want: false,
+		},
+		{
+			name: "updated after applied",
+			entries: []meta_v1.ManagedFieldsEntry{
+				apply,
+				{Manager: "kubectl-edit", Operation: meta_v1.ManagedFieldsOperationUpdate, Time: &after, FieldsV1: fields(edit)},
+			},
+			want: true,
+		},
+		{
+			name: "owned annotation updated after applied",
+			entries: []meta_v1.ManagedFieldsEntry{
+				apply,
+				{Manager: "kubectl-annotate", Operation: meta_v1.ManagedFieldsOperationUpdate, Time: &after,
+					FieldsV1: fields(`{"f:metadata":{"f:annotations":{"f:observability.udmire.cn/spec-hash":{}}}}`)},
+			},
+			want: true,
+		},
+		{
+			name: "revision annotated after applied",
+			entries: []meta_v1.ManagedFieldsEntry{
+				apply,
+				{Manager: "kube-controller-manager", Operation: meta_v1.ManagedFieldsOperationUpdate, Time: &after,
+					FieldsV1: fields(`{"f:metadata":{"f:annotations":{".":{},"f:deployment.kubernetes.io/revision":{}}}}`)},
+			},
+			want: false,
+		},
+		{
+			name: "status updated after applied",
+			entries: []meta_v1.ManagedFieldsEntry{
+				apply,
+				{Manager: "kube-controller-manager", Operation: meta_v1.ManagedFieldsOperationUpdate, Time: &after, Subresource: "status"},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &apps_v1.Deployment{ObjectMeta: meta_v1.ObjectMeta{ManagedFields: tt.entries}}
+			if got := modifiedSinceApplied(obj); got != tt.want {
+				t.Errorf("modifiedSinceApplied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_specHash(t *testing.T) {
+	cm := &v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: "node-exporter", Namespace: "default"}}
+	before, _ := specHash(cm)
+
+	cm.Annotations = map[string]string{utils.SpecHashAnnotation: "stale"}
+	if annotated, _ := specHash(cm); annotated != before {
+		t.Errorf("specHash() = %v, want the hash annotation ignored %v", annotated, before)
+	}
+
+	cm.Data = map[string]string{"config.yaml": "scrape_interval: 30s"}
+	if changed, _ := specHash(cm); changed == before {
+		t.Errorf("specHash() = %v, want a different hash after changes", changed)
+	}
+}
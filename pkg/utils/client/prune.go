@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/udmire/observability-operator/pkg/utils"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type inventoryKey struct {
+	gvk  schema.GroupVersionKind
+	name types.NamespacedName
+}
+
+// Inventory records the objects rendered for an instance, the objects owned by the
+// instance but missing from the inventory are orphans to be pruned.
+type Inventory struct {
+	c       client.Client
+	objects map[inventoryKey]struct{}
+}
+
+func NewInventory(c client.Client) *Inventory {
+	return &Inventory{
+		c:       c,
+		objects: make(map[inventoryKey]struct{}),
+	}
+}
+
+// Add records the objects in the inventory.
+func (i *Inventory) Add(objs ...client.Object) error {
+	for _, obj := range objs {
+		key, err := i.key(obj)
+		if err != nil {
+			return err
+		}
+		i.objects[key] = struct{}{}
+	}
+	return nil
+}
+
+// Contains reports whether the object is recorded in the inventory.
+func (i *Inventory) Contains(obj client.Object) bool {
+	if i == nil {
+		return false
+	}
+	key, err := i.key(obj)
+	if err != nil {
+		// Keep the objects which cannot be identified.
+		return true
+	}
+	_, exists := i.objects[key]
+	return exists
+}
+
+func (i *Inventory) key(obj client.Object) (inventoryKey, error) {
+	gvk, err := i.c.GroupVersionKindFor(obj)
+	if err != nil {
+		return inventoryKey{}, fmt.Errorf("failed to resolve the kind of %s: %w", obj.GetName(), err)
+	}
+	namespaced, err := i.c.IsObjectNamespaced(obj)
+	if err != nil {
+		return inventoryKey{}, fmt.Errorf("failed to resolve the scope of %s: %w", obj.GetName(), err)
+	}
+
+	key := inventoryKey{gvk: gvk, name: types.NamespacedName{Name: obj.GetName()}}
+	if namespaced {
+		key.name.Namespace = obj.GetNamespace()
+	}
+	return key, nil
+}
+
+// PruneOwned deletes the objects of the list kind matching the selector which are owned by
+// the uid alone and missing from the inventory. Objects annotated with the prune annotation
+// set to "false" are kept. The objects deleted are returned.
+func PruneOwned(ctx context.Context, c client.Client, list client.ObjectList, namespace string, selector labels.Selector, uid types.UID, inventory *Inventory) ([]client.Object, error) {
+	err := c.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %T: %w", list, err)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %T: %w", list, err)
+	}
+
+	var pruned []client.Object
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok || !obj.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if !ownedBy(obj, uid) || obj.GetAnnotations()[utils.PruneAnnotation] == "false" || inventory.Contains(obj) {
+			continue
+		}
+
+		err := c.Delete(ctx, obj, client.PropagationPolicy(meta_v1.DeletePropagationBackground))
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return pruned, fmt.Errorf("failed to prune %T %s: %w", obj, obj.GetName(), err)
+		}
+		pruned = append(pruned, obj)
+	}
+	return pruned, nil
+}
+
+// ownedBy reports whether the uid is the only owner of the object, objects shared with
+// other owners must not be deleted on behalf of one of them.
+func ownedBy(obj client.Object, uid types.UID) bool {
+	refs := obj.GetOwnerReferences()
+	return len(refs) == 1 && refs[0].UID == uid
+}
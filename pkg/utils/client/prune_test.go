@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/udmire/observability-operator/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPruneOwned(t *testing.T) {
+	owner := meta_v1.OwnerReference{APIVersion: "udmire.cn/v1alpha1", Kind: "Apps", Name: "apps", UID: types.UID("owner")}
+	other := meta_v1.OwnerReference{APIVersion: "udmire.cn/v1alpha1", Kind: "Apps", Name: "other", UID: types.UID("other")}
+	configMap := func(name, instance string, annotations map[string]string, owners ...meta_v1.OwnerReference) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			Labels:          utils.AppInstanceLabels(instance, "node-exporter", "1.5.0"),
+			Annotations:     annotations,
+			OwnerReferences: owners,
+		}}
+	}
+
+	rendered := configMap("rendered", "node", nil, owner)
+	c := fake.NewClientBuilder().WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)).WithObjects(
+		rendered,
+		configMap("dropped", "node", nil, owner),
+		configMap("kept", "node", map[string]string{utils.PruneAnnotation: "false"}, owner),
+		configMap("shared", "node", nil, owner, other),
+		configMap("foreign", "node", nil, other),
+		configMap("sibling", "kube", nil, owner),
+	).Build()
+
+	inventory := NewInventory(c)
+	if err := inventory.Add(rendered); err != nil {
+		t.Fatalf("Inventory.Add() error = %v", err)
+	}
+	pruned, err := PruneOwned(context.Background(), c, &v1.ConfigMapList{}, "default", utils.InstanceSelector("node"), owner.UID, inventory)
+	if err != nil {
+		t.Fatalf("PruneOwned() error = %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].GetName() != "dropped" {
+		t.Errorf("PruneOwned() pruned = %v, want [dropped]", pruned)
+	}
+
+	remaining := &v1.ConfigMapList{}
+	if err := c.List(context.Background(), remaining, client.InNamespace("default")); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var names []string
+	for _, cm := range remaining.Items {
+		names = append(names, cm.Name)
+	}
+	sort.Strings(names)
+	want := []string{"foreign", "kept", "rendered", "shared", "sibling"}
+	if len(names) != len(want) {
+		t.Fatalf("PruneOwned() remaining = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("PruneOwned() remaining = %v, want %v", names, want)
+			break
+		}
+	}
+}
@@ -9,6 +9,11 @@ const (
 	PartOfLabel    = "app.kubernetes.io/part-of"
 
 	DefaultManagedByValue = "observability-operator"
+
+	// PruneAnnotation set to "false" keeps an object which is no longer rendered for its instance.
+	PruneAnnotation = "observability.udmire.cn/prune"
+	// SpecHashAnnotation records the hash of the object last applied by the operator.
+	SpecHashAnnotation = "observability.udmire.cn/spec-hash"
 )
 
 var SelectorIgnoredLabels = []string{ManagedByLabel, VersionLabel, PartOfLabel}
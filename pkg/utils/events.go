@@ -0,0 +1,14 @@
+package utils
+
+// Reasons of the events recorded on the managed instances.
+const (
+	EventReasonTemplateNotFound = "TemplateNotFound"
+	EventReasonInvalidTemplate  = "InvalidTemplate"
+	EventReasonInvalidValues    = "InvalidValues"
+	EventReasonDependencyFailed = "DependencyFailed"
+	EventReasonApplyFailed      = "ApplyFailed"
+	EventReasonCreated          = "Created"
+	EventReasonUpdated          = "Updated"
+	EventReasonPruned           = "Pruned"
+	EventReasonCleanedUp        = "CleanedUp"
+)
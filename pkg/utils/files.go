@@ -2,12 +2,12 @@ package utils
 
 import (
 	"io/fs"
-	"os"
 )
 
-func HasOnlySubDirectory(parent, name string) (bool, error) {
+// HasOnlySubDirectory returns whether the root of the file system holds nothing but the directory.
+func HasOnlySubDirectory(fsys fs.FS, name string) (bool, error) {
 	var has, hasAnother bool
-	err := fs.WalkDir(os.DirFS(parent), ".", func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -1,9 +1,9 @@
 package utils
 
 import (
-	"os"
-	"path/filepath"
+	"io/fs"
 	"testing"
+	"testing/fstest"
 )
 
 func TestHasOnlySubDirectory(t *testing.T) {
@@ -56,12 +56,11 @@ func TestHasOnlySubDirectory(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tempDir, _ := os.MkdirTemp("", "xxx-")
-			defer os.RemoveAll(tempDir)
+			fsys := fstest.MapFS{}
 			for _, name := range tt.args.dirs {
-				os.Mkdir(filepath.Join(tempDir, name), os.ModeDir)
+				fsys[name] = &fstest.MapFile{Mode: fs.ModeDir}
 			}
-			got, err := HasOnlySubDirectory(tempDir, tt.args.name)
+			got, err := HasOnlySubDirectory(fsys, tt.args.name)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HasOnlySubDirectory() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -3,6 +3,9 @@ package utils
 import (
 	"regexp"
 	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 )
 
 const (
@@ -33,6 +36,24 @@ func ComponentLabels(instance, template, version, component string) map[string]s
 	return ils
 }
 
+// InstanceSelector selects the objects managed for the named instance.
+func InstanceSelector(instance string) labels.Selector {
+	return labels.SelectorFromSet(labels.Set{
+		InstanceLabel:  instance,
+		ManagedByLabel: DefaultManagedByValue,
+	})
+}
+
+// OrphanedInstancesSelector selects the objects managed for instances other than the named ones.
+func OrphanedInstancesSelector(instances []string) labels.Selector {
+	managed, _ := labels.NewRequirement(ManagedByLabel, selection.Equals, []string{DefaultManagedByValue})
+	orphaned, _ := labels.NewRequirement(InstanceLabel, selection.Exists, nil)
+	if len(instances) > 0 {
+		orphaned, _ = labels.NewRequirement(InstanceLabel, selection.NotIn, instances)
+	}
+	return labels.NewSelector().Add(*managed, *orphaned)
+}
+
 func UpdateImageRegistry(registry, image string) string {
 	splits := strings.Split(image, PATHS)
 	if len(splits) < 2 {
@@ -1,6 +1,10 @@
 package utils
 
-import "testing"
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
 
 func TestUpdateImageRegistry(t *testing.T) {
 	type args struct {
@@ -77,3 +81,43 @@ func TestUpdateImageRegistry(t *testing.T) {
 		})
 	}
 }
+
+func TestOrphanedInstancesSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		instances []string
+		labels    map[string]string
+		want      bool
+	}{
+		{
+			name:      "removed",
+			instances: []string{"node-exporter"},
+			labels:    AppInstanceLabels("kube-state-metrics", "kube-state-metrics", "2.9.2"),
+			want:      true,
+		},
+		{
+			name:      "current",
+			instances: []string{"node-exporter"},
+			labels:    AppInstanceLabels("node-exporter", "node-exporter", "1.5.0"),
+			want:      false,
+		},
+		{
+			name:   "all_removed",
+			labels: AppInstanceLabels("node-exporter", "node-exporter", "1.5.0"),
+			want:   true,
+		},
+		{
+			name:      "unmanaged",
+			instances: []string{"node-exporter"},
+			labels:    map[string]string{InstanceLabel: "kube-state-metrics"},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OrphanedInstancesSelector(tt.instances).Matches(labels.Set(tt.labels)); got != tt.want {
+				t.Errorf("OrphanedInstancesSelector().Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,27 +1,101 @@
 package utils
 
-import "strings"
+import (
+	"regexp"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
+)
+
+// wildcardRegex matches the wildcard components of the version constraints, e.g. "1.x" or "2.*".
+var wildcardRegex = regexp.MustCompile(`(^|\.)[xX*](\.|$)`)
+
+// IsNewerThan reports whether the version is newer than the previous one by the semantic
+// versioning precedence, so that "1.10" is newer than "1.9" and "1.0.0-beta" is older than
+// "1.0.0". Versions which are not semantic versions are older than all the semantic ones.
 func IsNewerThan(version, previous string) bool {
-	version = strings.TrimLeft(strings.ToLower(version), "v")
-	previous = strings.TrimLeft(strings.ToLower(previous), "v")
+	return compareVersions(version, previous) > 0
+}
 
-	versionParts := strings.Split(version, ".")
-	previousParts := strings.Split(previous, ".")
+// ResolveVersion returns the highest of the versions satisfying the constraint, e.g. "~1.4", "^2"
+// or ">=1.2 <2". A constraint equal to one of the versions selects that version as is, and an
+// empty constraint selects the latest release, or the latest pre-release when there is no release.
+func ResolveVersion(versions []string, constraint string) (string, bool) {
+	constraint = strings.TrimSpace(constraint)
+	for _, version := range versions {
+		if len(constraint) > 0 && version == constraint {
+			return version, true
+		}
+	}
 
-	length := len(versionParts)
-	if len(previousParts) < length {
-		length = len(previousParts)
+	var match func(string) bool
+	if len(constraint) == 0 {
+		match = func(string) bool { return true }
+		if release, ok := latestVersion(versions, isRelease); ok {
+			return release, true
+		}
+	} else {
+		constraints, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return "", false
+		}
+		match = func(version string) bool {
+			parsed, err := parseVersion(version)
+			return err == nil && constraints.Check(parsed)
+		}
 	}
+	return latestVersion(versions, match)
+}
 
-	for i := 0; i < length; i++ {
-		if versionParts[i] > previousParts[i] {
-			return true
-		} else if versionParts[i] < previousParts[i] {
-			return false
+func latestVersion(versions []string, match func(string) bool) (string, bool) {
+	var latest string
+	found := false
+	for _, version := range versions {
+		if !match(version) {
+			continue
+		}
+		if !found || IsNewerThan(version, latest) {
+			latest, found = version, true
 		}
-		continue
 	}
+	return latest, found
+}
+
+func isRelease(version string) bool {
+	parsed, err := parseVersion(version)
+	return err == nil && len(parsed.Prerelease()) == 0
+}
+
+func parseVersion(version string) (*semver.Version, error) {
+	return semver.NewVersion(strings.ToLower(version))
+}
 
-	return len(versionParts) >= len(previousParts)
+// compareVersions compares the versions by the semantic versioning precedence, the versions
+// which cannot be parsed are ordered before the others and compared as strings among themselves.
+func compareVersions(version, other string) int {
+	parsed, err := parseVersion(version)
+	parsedOther, errOther := parseVersion(other)
+	switch {
+	case err == nil && errOther == nil:
+		return parsed.Compare(parsedOther)
+	case err == nil:
+		return 1
+	case errOther == nil:
+		return -1
+	}
+	return strings.Compare(version, other)
+}
+
+// ConstraintType returns the type of the version constraint: "latest" when empty, "exact" for a
+// version and "range" otherwise, bounded so that it can label the metrics.
+func ConstraintType(constraint string) string {
+	constraint = strings.TrimSpace(constraint)
+	switch {
+	case len(constraint) == 0:
+		return "latest"
+	case strings.ContainsAny(constraint, "<>=~^!|, ") || wildcardRegex.MatchString(constraint):
+		return "range"
+	default:
+		return "exact"
+	}
 }
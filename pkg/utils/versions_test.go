@@ -0,0 +1,81 @@
+package utils
+
+import "testing"
+
+func TestIsNewerThan(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		previous string
+		want     bool
+	}{
+		{name: "patch", version: "v1.0.2", previous: "v1.0.1", want: true},
+		{name: "numeric", version: "1.10", previous: "1.9", want: true},
+		{name: "older", version: "v1.9.0", previous: "v1.10.0", want: false},
+		{name: "equal", version: "v1.0.1", previous: "1.0.1", want: false},
+		{name: "release over pre-release", version: "v1.0.0", previous: "v1.0.0-beta", want: true},
+		{name: "beta over alpha", version: "v1.0.0-beta", previous: "v1.0.0-alpha", want: true},
+		{name: "invalid", version: "v1.0.1.1", previous: "v1.0.0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNewerThan(tt.version, tt.previous); got != tt.want {
+				t.Errorf("IsNewerThan(%q, %q) = %v, want %v", tt.version, tt.previous, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	versions := []string{"v1.2.0", "v1.4.1", "v1.4.3", "v1.9.0", "v1.10.0", "v2.0.0", "v2.1.0-beta", "v1.0.1.1"}
+	tests := []struct {
+		name       string
+		versions   []string
+		constraint string
+		want       string
+		wantFound  bool
+	}{
+		{name: "latest", versions: versions, constraint: "", want: "v2.0.0", wantFound: true},
+		{name: "latest pre-release", versions: []string{"v1.0.0-alpha", "v1.0.0-beta"}, want: "v1.0.0-beta", wantFound: true},
+		{name: "exact", versions: versions, constraint: "v1.4.1", want: "v1.4.1", wantFound: true},
+		{name: "exact invalid semver", versions: versions, constraint: "v1.0.1.1", want: "v1.0.1.1", wantFound: true},
+		{name: "tilde", versions: versions, constraint: "~1.4", want: "v1.4.3", wantFound: true},
+		{name: "caret", versions: versions, constraint: "^1", want: "v1.10.0", wantFound: true},
+		{name: "range", versions: versions, constraint: ">=1.2 <1.10", want: "v1.9.0", wantFound: true},
+		{name: "pre-release", versions: versions, constraint: ">=2.1.0-alpha", want: "v2.1.0-beta", wantFound: true},
+		{name: "unsatisfied", versions: versions, constraint: "^3", wantFound: false},
+		{name: "invalid constraint", versions: versions, constraint: "latest", wantFound: false},
+		{name: "empty", constraint: "", wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := ResolveVersion(tt.versions, tt.constraint)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("ResolveVersion() = %q, %v, want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestConstraintType(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{constraint: "", want: "latest"},
+		{constraint: "v1.4.1", want: "exact"},
+		{constraint: "v1.0.1.1", want: "exact"},
+		{constraint: "~1.4", want: "range"},
+		{constraint: "^1", want: "range"},
+		{constraint: ">=1.2 <1.10", want: "range"},
+		{constraint: "1.x", want: "range"},
+		{constraint: "2.*", want: "range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			if got := ConstraintType(tt.constraint); got != tt.want {
+				t.Errorf("ConstraintType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}